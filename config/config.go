@@ -3,13 +3,16 @@ package config
 import (
 	"fmt"
 	"github.com/ilyakaznacheev/cleanenv"
+	"time"
 )
 
 type (
 	Config struct {
-		HTTP `yaml:"http"`
-		Log  `yaml:"log"`
-		PG   `yaml:"postgres"`
+		HTTP      `yaml:"http"`
+		Log       `yaml:"log"`
+		PG        `yaml:"postgres"`
+		Auth      `yaml:"auth"`
+		Scheduler `yaml:"scheduler"`
 	}
 
 	HTTP struct {
@@ -25,6 +28,15 @@ type (
 		URL         string `env-required:"true" env:"POSTGRES_CONN" env-upd:""`
 		MaxPoolSize int    `yaml:"max_pool_size"`
 	}
+
+	Auth struct {
+		SignKey          string `env:"AUTH_SIGN_KEY" env-upd:""`
+		UsernameFallback bool   `yaml:"username_fallback" env:"AUTH_USERNAME_FALLBACK" env-upd:""`
+	}
+
+	Scheduler struct {
+		DeadlineInterval time.Duration `yaml:"deadline_interval" env:"SCHEDULER_DEADLINE_INTERVAL" env-default:"1m" env-upd:""`
+	}
 )
 
 func NewConfig(configPath string) (*Config, error) {
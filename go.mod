@@ -7,6 +7,7 @@ toolchain go1.22.7
 require (
 	github.com/Masterminds/squirrel v1.5.4
 	github.com/go-playground/validator v9.31.0+incompatible
+	github.com/golang-jwt/jwt/v5 v5.2.1
 	github.com/golang-migrate/migrate/v4 v4.18.1
 	github.com/google/uuid v1.6.0
 	github.com/ilyakaznacheev/cleanenv v1.5.0
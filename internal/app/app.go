@@ -2,12 +2,14 @@ package app
 
 import (
 	"avito/config"
+	"avito/internal/authz"
 	v1 "avito/internal/controllers/http/v1"
 	"avito/internal/controllers/validators"
 	"avito/internal/repo"
 	"avito/internal/service"
 	"avito/pkg/httpserver"
 	"avito/pkg/postgres"
+	"avito/pkg/scheduler"
 	"context"
 	"fmt"
 	"github.com/labstack/echo/v4"
@@ -27,6 +29,13 @@ func Run(configPath string) {
 	// Logger
 	SetLogrus(cfg.Log.Level)
 
+	if cfg.Auth.SignKey == "" && !cfg.Auth.UsernameFallback {
+		log.Fatal("app - Run - AUTH_SIGN_KEY is required when username fallback is disabled")
+	}
+	if cfg.Scheduler.DeadlineInterval <= 0 {
+		log.Fatal("app - Run - scheduler deadline interval must be positive")
+	}
+
 	// Repositories
 	log.Info("Initializing postgres...")
 	pg, err := postgres.New(cfg.PG.URL, postgres.MaxPoolSize(cfg.PG.MaxPoolSize))
@@ -48,14 +57,19 @@ func Run(configPath string) {
 	// Services dependencies
 	log.Info("Initializing services...")
 	deps := service.ServicesDependencies{
-		Repos: repositories,
+		Repos:   repositories,
+		SignKey: cfg.Auth.SignKey,
 	}
 	services := service.NewServices(deps)
 
+	// Authorization
+	log.Info("Initializing authorization...")
+	policy := authz.New(repositories.Employee, repositories.Tender, repositories.Organization)
+
 	// Echo handler
 	log.Info("Initializing handlers and routes...")
 	handler := echo.New()
-	v1.NewRouter(handler, services)
+	v1.NewRouter(handler, services, policy, cfg.Auth.UsernameFallback)
 	handler.Validator = validators.New()
 
 	// HTTP server
@@ -63,6 +77,18 @@ func Run(configPath string) {
 	log.Debugf("Server port: %s", cfg.HTTP.Address)
 	httpServer := httpserver.New(handler, httpserver.Port(cfg.HTTP.Port))
 
+	// Deadline scheduler
+	log.Info("Starting deadline scheduler...")
+	deadlineScheduler := scheduler.New(func(ctx context.Context) {
+		closed, err := services.Tender.CloseExpiredTenders(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Error(fmt.Errorf("app - Run - services.Tender.CloseExpiredTenders: %w", err))
+		}
+		if closed > 0 {
+			log.Infof("Closed %d expired tenders", closed)
+		}
+	}, scheduler.Interval(cfg.Scheduler.DeadlineInterval))
+
 	// Waiting signal
 	log.Info("Configuring graceful shutdown...")
 	interrupt := make(chan os.Signal, 1)
@@ -81,4 +107,8 @@ func Run(configPath string) {
 	if err != nil {
 		log.Error(fmt.Errorf("app - Run - httpServer.Shutdown: %w", err))
 	}
+	err = deadlineScheduler.Shutdown()
+	if err != nil {
+		log.Error(fmt.Errorf("app - Run - deadlineScheduler.Shutdown: %w", err))
+	}
 }
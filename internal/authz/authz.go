@@ -0,0 +1,196 @@
+package authz
+
+import (
+	"avito/internal/entity"
+	"avito/internal/repo"
+	"avito/internal/repo/repoerrs"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+)
+
+// Principal is the caller a decision is made for. Callers authenticated with
+// an organization API key act as the employee who created the key, but only
+// within the key's organization.
+type Principal struct {
+	EmployeeId uuid.UUID
+	// ApiKeyOrganizationId is the organization of the API key the request was
+	// made with and is nil for employees.
+	ApiKeyOrganizationId *uuid.UUID
+}
+
+// EmployeePrincipal is an employee acting on their own behalf.
+func EmployeePrincipal(employeeId uuid.UUID) Principal {
+	return Principal{EmployeeId: employeeId}
+}
+
+// ApiKeyPrincipal is an API key of the organization created by the employee.
+func ApiKeyPrincipal(employeeId, organizationId uuid.UUID) Principal {
+	return Principal{EmployeeId: employeeId, ApiKeyOrganizationId: &organizationId}
+}
+
+func (p Principal) IsApiKey() bool {
+	return p.ApiKeyOrganizationId != nil
+}
+
+// inScope reports whether the principal may act for the organization at all.
+func (p Principal) inScope(organizationId uuid.UUID) bool {
+	return !p.IsApiKey() || *p.ApiKeyOrganizationId == organizationId
+}
+
+// Policy decides what a principal may do with tenders and bids. Every method
+// returns false without an error when access is denied; an error means the
+// decision could not be made.
+type Policy interface {
+	CanViewTender(ctx context.Context, p Principal, tender *entity.Tender) (bool, error)
+	CanEditTender(ctx context.Context, p Principal, tender *entity.Tender) (bool, error)
+	CanViewBid(ctx context.Context, p Principal, bid *entity.Bid) (bool, error)
+	CanEditBid(ctx context.Context, p Principal, bid *entity.Bid) (bool, error)
+	CanDecide(ctx context.Context, p Principal, tender *entity.Tender) (bool, error)
+	CanViewOrganization(ctx context.Context, p Principal, organizationId uuid.UUID) (bool, error)
+	CanManageOrganization(ctx context.Context, p Principal, organizationId uuid.UUID) (bool, error)
+	CanEditEmployee(ctx context.Context, p Principal, targetId uuid.UUID) (bool, error)
+	CanDeactivateEmployee(ctx context.Context, p Principal, targetId uuid.UUID) (bool, error)
+}
+
+type Authorizer struct {
+	employeeRepo     repo.Employee
+	tenderRepo       repo.Tender
+	organizationRepo repo.Organization
+}
+
+func New(employeeRepo repo.Employee, tenderRepo repo.Tender, organizationRepo repo.Organization) *Authorizer {
+	return &Authorizer{
+		employeeRepo:     employeeRepo,
+		tenderRepo:       tenderRepo,
+		organizationRepo: organizationRepo,
+	}
+}
+
+// CanViewTender allows everyone to see published tenders and only the
+// responsibles of the organization to see the others.
+func (a *Authorizer) CanViewTender(ctx context.Context, p Principal, tender *entity.Tender) (bool, error) {
+	if tender.Status == "Published" {
+		return true, nil
+	}
+	return a.isResponsible(ctx, p, tender.OrganizationId)
+}
+
+func (a *Authorizer) CanEditTender(ctx context.Context, p Principal, tender *entity.Tender) (bool, error) {
+	return a.isResponsible(ctx, p, tender.OrganizationId)
+}
+
+// CanViewBid allows the bid's authors to see it in any status and the
+// responsibles of the tender's organization to see it once published.
+func (a *Authorizer) CanViewBid(ctx context.Context, p Principal, bid *entity.Bid) (bool, error) {
+	ok, err := a.CanEditBid(ctx, p, bid)
+	if err != nil || ok {
+		return ok, err
+	}
+	if bid.Status != "Published" {
+		return false, nil
+	}
+	tender, err := a.tenderRepo.GetTenderById(ctx, bid.TenderId)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("Authorizer.CanViewBid - a.tenderRepo.GetTenderById: %v", err)
+	}
+	return a.isResponsible(ctx, p, tender.OrganizationId)
+}
+
+// CanEditBid allows the author of a user bid, and every responsible of the
+// organization an organization bid was submitted for. API keys only reach
+// the bids of their own organization.
+func (a *Authorizer) CanEditBid(ctx context.Context, p Principal, bid *entity.Bid) (bool, error) {
+	switch bid.AuthorType {
+	case "User":
+		return !p.IsApiKey() && bid.AuthorId == p.EmployeeId, nil
+	case "Organization":
+		if bid.OrganizationId == nil {
+			return !p.IsApiKey() && bid.AuthorId == p.EmployeeId, nil
+		}
+		return a.isResponsible(ctx, p, *bid.OrganizationId)
+	}
+	return false, nil
+}
+
+// CanDecide allows the responsibles of the tender's organization to decide on
+// and review the bids submitted to it. Decisions count towards the approval
+// quorum of people, so API keys never make them.
+func (a *Authorizer) CanDecide(ctx context.Context, p Principal, tender *entity.Tender) (bool, error) {
+	if p.IsApiKey() {
+		return false, nil
+	}
+	return a.isResponsible(ctx, p, tender.OrganizationId)
+}
+
+// CanViewOrganization allows every responsible of the organization to see its
+// responsibles.
+func (a *Authorizer) CanViewOrganization(ctx context.Context, p Principal, organizationId uuid.UUID) (bool, error) {
+	return a.isResponsible(ctx, p, organizationId)
+}
+
+// CanManageOrganization allows the admins of the organization to edit or
+// delete it, assign its responsibles and manage its API keys. API keys cannot
+// manage organizations, not even to create further keys.
+func (a *Authorizer) CanManageOrganization(ctx context.Context, p Principal, organizationId uuid.UUID) (bool, error) {
+	if p.IsApiKey() {
+		return false, nil
+	}
+	role, err := a.organizationRepo.GetResponsibleRole(ctx, organizationId, p.EmployeeId)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("Authorizer.CanManageOrganization - a.organizationRepo.GetResponsibleRole: %v", err)
+	}
+	return role == entity.RoleAdmin, nil
+}
+
+// CanEditEmployee allows employees to change only their own profile.
+func (a *Authorizer) CanEditEmployee(ctx context.Context, p Principal, targetId uuid.UUID) (bool, error) {
+	return !p.IsApiKey() && p.EmployeeId == targetId, nil
+}
+
+// CanDeactivateEmployee allows employees to deactivate themselves and the
+// admins of any organization they are responsible for to deactivate them.
+func (a *Authorizer) CanDeactivateEmployee(ctx context.Context, p Principal, targetId uuid.UUID) (bool, error) {
+	if p.IsApiKey() {
+		return false, nil
+	}
+	if p.EmployeeId == targetId {
+		return true, nil
+	}
+	targetOrgs, err := a.employeeRepo.GetEmployeeOrgIdsById(ctx, targetId)
+	if err != nil {
+		return false, fmt.Errorf("Authorizer.CanDeactivateEmployee - a.employeeRepo.GetEmployeeOrgIdsById: %v", err)
+	}
+	for _, orgId := range targetOrgs {
+		ok, err := a.CanManageOrganization(ctx, p, orgId)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+// isResponsible checks the employee's membership in this one organization, so
+// employees responsible for several organizations are allowed in each of them.
+// API keys are confined to their organization even where their creator is
+// responsible for others.
+func (a *Authorizer) isResponsible(ctx context.Context, p Principal, organizationId uuid.UUID) (bool, error) {
+	if !p.inScope(organizationId) {
+		return false, nil
+	}
+	_, err := a.organizationRepo.GetResponsibleRole(ctx, organizationId, p.EmployeeId)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("Authorizer.isResponsible - a.organizationRepo.GetResponsibleRole: %v", err)
+	}
+	return true, nil
+}
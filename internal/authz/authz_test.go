@@ -0,0 +1,378 @@
+package authz
+
+import (
+	"avito/internal/entity"
+	"avito/internal/repo"
+	"avito/internal/repo/repoerrs"
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"testing"
+)
+
+var errDB = errors.New("db is down")
+
+// fakeEmployeeRepo serves organization memberships from a map. Methods the
+// policy does not use panic through the nil embedded interface.
+type fakeEmployeeRepo struct {
+	repo.Employee
+	orgs map[uuid.UUID][]uuid.UUID
+	err  error
+}
+
+func (f *fakeEmployeeRepo) GetEmployeeOrgIdsById(_ context.Context, employeeId uuid.UUID) ([]uuid.UUID, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.orgs[employeeId], nil
+}
+
+type fakeTenderRepo struct {
+	repo.Tender
+	tenders map[uuid.UUID]*entity.Tender
+	err     error
+}
+
+func (f *fakeTenderRepo) GetTenderById(_ context.Context, tenderId uuid.UUID) (*entity.Tender, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	t, ok := f.tenders[tenderId]
+	if !ok {
+		return nil, repoerrs.ErrNotFound
+	}
+	return t, nil
+}
+
+type responsibleKey struct {
+	organizationId, userId uuid.UUID
+}
+
+type fakeOrganizationRepo struct {
+	repo.Organization
+	roles map[responsibleKey]string
+	err   error
+}
+
+func (f *fakeOrganizationRepo) GetResponsibleRole(_ context.Context, organizationId, userId uuid.UUID) (string, error) {
+	if f.err != nil {
+		return "", f.err
+	}
+	role, ok := f.roles[responsibleKey{organizationId, userId}]
+	if !ok {
+		return "", repoerrs.ErrNotFound
+	}
+	return role, nil
+}
+
+// fixture is a tender organization with an admin and a member, a bidding
+// organization with one member, an employee responsible for both and an
+// outsider responsible for nothing.
+type fixture struct {
+	org, bidderOrg                               uuid.UUID
+	admin, member, creator, bidder, multi, other uuid.UUID
+	tender                                       *entity.Tender
+}
+
+func newFixture() fixture {
+	f := fixture{
+		org:       uuid.New(),
+		bidderOrg: uuid.New(),
+		admin:     uuid.New(),
+		member:    uuid.New(),
+		creator:   uuid.New(),
+		bidder:    uuid.New(),
+		multi:     uuid.New(),
+		other:     uuid.New(),
+	}
+	f.tender = &entity.Tender{Id: uuid.New(), OrganizationId: f.org, Status: "Created", CreatorUsername: "creator"}
+	return f
+}
+
+func (f fixture) authorizer() *Authorizer {
+	return New(
+		&fakeEmployeeRepo{orgs: map[uuid.UUID][]uuid.UUID{
+			f.admin:   {f.org},
+			f.member:  {f.org},
+			f.creator: {f.org},
+			f.bidder:  {f.bidderOrg},
+			f.multi:   {f.org, f.bidderOrg},
+		}},
+		&fakeTenderRepo{tenders: map[uuid.UUID]*entity.Tender{f.tender.Id: f.tender}},
+		&fakeOrganizationRepo{roles: map[responsibleKey]string{
+			{f.org, f.admin}:        entity.RoleAdmin,
+			{f.org, f.member}:       entity.RoleMember,
+			{f.org, f.creator}:      entity.RoleMember,
+			{f.bidderOrg, f.bidder}: entity.RoleMember,
+			{f.org, f.multi}:        entity.RoleMember,
+			{f.bidderOrg, f.multi}:  entity.RoleMember,
+		}},
+	)
+}
+
+func (f fixture) failing() *Authorizer {
+	return New(&fakeEmployeeRepo{err: errDB}, &fakeTenderRepo{err: errDB}, &fakeOrganizationRepo{err: errDB})
+}
+
+func check(t *testing.T, allowed, wantAllowed bool, err error, wantErr bool) {
+	t.Helper()
+	if wantErr {
+		if err == nil {
+			t.Fatalf("expected an error, got allowed=%v", allowed)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if allowed != wantAllowed {
+		t.Fatalf("allowed = %v, want %v", allowed, wantAllowed)
+	}
+}
+
+func TestCanViewTender(t *testing.T) {
+	f := newFixture()
+	published := *f.tender
+	published.Status = "Published"
+	tests := []struct {
+		name    string
+		authz   *Authorizer
+		p       Principal
+		tender  *entity.Tender
+		allowed bool
+		wantErr bool
+	}{
+		{"published tender is public", f.authorizer(), EmployeePrincipal(f.other), &published, true, false},
+		{"published tender needs no lookup", f.failing(), EmployeePrincipal(f.other), &published, true, false},
+		{"responsible sees created tender", f.authorizer(), EmployeePrincipal(f.member), f.tender, true, false},
+		{"outsider cannot see created tender", f.authorizer(), EmployeePrincipal(f.other), f.tender, false, false},
+		{"responsible of another organization", f.authorizer(), EmployeePrincipal(f.bidder), f.tender, false, false},
+		{"api key sees published tender", f.authorizer(), ApiKeyPrincipal(f.bidder, f.bidderOrg), &published, true, false},
+		{"api key of the tender organization", f.authorizer(), ApiKeyPrincipal(f.member, f.org), f.tender, true, false},
+		{"api key of another organization of the creator", f.authorizer(), ApiKeyPrincipal(f.multi, f.bidderOrg), f.tender, false, false},
+		{"repo error", f.failing(), EmployeePrincipal(f.member), f.tender, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, err := tt.authz.CanViewTender(context.Background(), tt.p, tt.tender)
+			check(t, allowed, tt.allowed, err, tt.wantErr)
+		})
+	}
+}
+
+// TestCanEditTender pins down that editing a tender, changing its status and
+// rolling it back is open to every responsible of the organization, not only
+// to the employee who created it.
+func TestCanEditTender(t *testing.T) {
+	f := newFixture()
+	tests := []struct {
+		name    string
+		authz   *Authorizer
+		p       Principal
+		allowed bool
+		wantErr bool
+	}{
+		{"creator", f.authorizer(), EmployeePrincipal(f.creator), true, false},
+		{"member who did not create the tender", f.authorizer(), EmployeePrincipal(f.member), true, false},
+		{"admin", f.authorizer(), EmployeePrincipal(f.admin), true, false},
+		{"responsible of another organization", f.authorizer(), EmployeePrincipal(f.bidder), false, false},
+		{"outsider", f.authorizer(), EmployeePrincipal(f.other), false, false},
+		{"responsible of both organizations", f.authorizer(), EmployeePrincipal(f.multi), true, false},
+		{"api key of the tender organization", f.authorizer(), ApiKeyPrincipal(f.member, f.org), true, false},
+		{"api key of another organization of the creator", f.authorizer(), ApiKeyPrincipal(f.multi, f.bidderOrg), false, false},
+		{"api key scope needs no lookup", f.failing(), ApiKeyPrincipal(f.multi, f.bidderOrg), false, false},
+		{"repo error", f.failing(), EmployeePrincipal(f.member), false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, err := tt.authz.CanEditTender(context.Background(), tt.p, f.tender)
+			check(t, allowed, tt.allowed, err, tt.wantErr)
+		})
+	}
+}
+
+func TestCanEditBid(t *testing.T) {
+	f := newFixture()
+	userBid := &entity.Bid{Id: uuid.New(), TenderId: f.tender.Id, AuthorType: "User", AuthorId: f.other, Status: "Created"}
+	orgBid := &entity.Bid{Id: uuid.New(), TenderId: f.tender.Id, AuthorType: "Organization", AuthorId: f.bidder, OrganizationId: &f.bidderOrg, Status: "Created"}
+	legacyBid := &entity.Bid{Id: uuid.New(), TenderId: f.tender.Id, AuthorType: "Organization", AuthorId: f.bidder, Status: "Created"}
+	tests := []struct {
+		name    string
+		authz   *Authorizer
+		p       Principal
+		bid     *entity.Bid
+		allowed bool
+		wantErr bool
+	}{
+		{"author of a user bid", f.authorizer(), EmployeePrincipal(f.other), userBid, true, false},
+		{"someone else on a user bid", f.authorizer(), EmployeePrincipal(f.member), userBid, false, false},
+		{"user bid needs no lookup", f.failing(), EmployeePrincipal(f.other), userBid, true, false},
+		{"responsible of the bidding organization", f.authorizer(), EmployeePrincipal(f.bidder), orgBid, true, false},
+		{"responsible of the tender organization", f.authorizer(), EmployeePrincipal(f.member), orgBid, false, false},
+		{"author of a bid without organization", f.authorizer(), EmployeePrincipal(f.bidder), legacyBid, true, false},
+		{"non-author of a bid without organization", f.authorizer(), EmployeePrincipal(f.member), legacyBid, false, false},
+		{"api key of the bidding organization", f.authorizer(), ApiKeyPrincipal(f.bidder, f.bidderOrg), orgBid, true, false},
+		{"api key of another organization of the creator", f.authorizer(), ApiKeyPrincipal(f.multi, f.org), orgBid, false, false},
+		{"api key on its creator's user bid", f.authorizer(), ApiKeyPrincipal(f.other, f.org), userBid, false, false},
+		{"api key on a bid without organization", f.authorizer(), ApiKeyPrincipal(f.bidder, f.bidderOrg), legacyBid, false, false},
+		{"unknown author type", f.authorizer(), EmployeePrincipal(f.other), &entity.Bid{AuthorType: "Robot", AuthorId: f.other}, false, false},
+		{"repo error", f.failing(), EmployeePrincipal(f.bidder), orgBid, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, err := tt.authz.CanEditBid(context.Background(), tt.p, tt.bid)
+			check(t, allowed, tt.allowed, err, tt.wantErr)
+		})
+	}
+}
+
+func TestCanViewBid(t *testing.T) {
+	f := newFixture()
+	created := &entity.Bid{Id: uuid.New(), TenderId: f.tender.Id, AuthorType: "User", AuthorId: f.other, Status: "Created"}
+	published := &entity.Bid{Id: uuid.New(), TenderId: f.tender.Id, AuthorType: "User", AuthorId: f.other, Status: "Published"}
+	orphan := &entity.Bid{Id: uuid.New(), TenderId: uuid.New(), AuthorType: "User", AuthorId: f.other, Status: "Published"}
+	tests := []struct {
+		name    string
+		authz   *Authorizer
+		p       Principal
+		bid     *entity.Bid
+		allowed bool
+		wantErr bool
+	}{
+		{"author sees created bid", f.authorizer(), EmployeePrincipal(f.other), created, true, false},
+		{"tender responsible cannot see created bid", f.authorizer(), EmployeePrincipal(f.member), created, false, false},
+		{"tender responsible sees published bid", f.authorizer(), EmployeePrincipal(f.member), published, true, false},
+		{"outsider cannot see published bid", f.authorizer(), EmployeePrincipal(f.bidder), published, false, false},
+		{"api key of the tender organization sees published bid", f.authorizer(), ApiKeyPrincipal(f.member, f.org), published, true, false},
+		{"api key of another organization of the creator", f.authorizer(), ApiKeyPrincipal(f.multi, f.bidderOrg), published, false, false},
+		{"missing tender", f.authorizer(), EmployeePrincipal(f.member), orphan, false, false},
+		{"repo error", f.failing(), EmployeePrincipal(f.member), published, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, err := tt.authz.CanViewBid(context.Background(), tt.p, tt.bid)
+			check(t, allowed, tt.allowed, err, tt.wantErr)
+		})
+	}
+}
+
+func TestCanDecide(t *testing.T) {
+	f := newFixture()
+	tests := []struct {
+		name    string
+		authz   *Authorizer
+		p       Principal
+		allowed bool
+		wantErr bool
+	}{
+		{"member", f.authorizer(), EmployeePrincipal(f.member), true, false},
+		{"admin", f.authorizer(), EmployeePrincipal(f.admin), true, false},
+		{"bidder", f.authorizer(), EmployeePrincipal(f.bidder), false, false},
+		{"api key of the tender organization", f.authorizer(), ApiKeyPrincipal(f.member, f.org), false, false},
+		{"outsider", f.authorizer(), EmployeePrincipal(f.other), false, false},
+		{"repo error", f.failing(), EmployeePrincipal(f.member), false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, err := tt.authz.CanDecide(context.Background(), tt.p, f.tender)
+			check(t, allowed, tt.allowed, err, tt.wantErr)
+		})
+	}
+}
+
+func TestCanViewOrganization(t *testing.T) {
+	f := newFixture()
+	tests := []struct {
+		name    string
+		authz   *Authorizer
+		p       Principal
+		allowed bool
+		wantErr bool
+	}{
+		{"admin", f.authorizer(), EmployeePrincipal(f.admin), true, false},
+		{"member", f.authorizer(), EmployeePrincipal(f.member), true, false},
+		{"responsible of another organization", f.authorizer(), EmployeePrincipal(f.bidder), false, false},
+		{"outsider", f.authorizer(), EmployeePrincipal(f.other), false, false},
+		{"api key of the organization", f.authorizer(), ApiKeyPrincipal(f.member, f.org), true, false},
+		{"api key of another organization of the creator", f.authorizer(), ApiKeyPrincipal(f.multi, f.bidderOrg), false, false},
+		{"repo error", f.failing(), EmployeePrincipal(f.member), false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, err := tt.authz.CanViewOrganization(context.Background(), tt.p, f.org)
+			check(t, allowed, tt.allowed, err, tt.wantErr)
+		})
+	}
+}
+
+func TestCanManageOrganization(t *testing.T) {
+	f := newFixture()
+	tests := []struct {
+		name    string
+		authz   *Authorizer
+		p       Principal
+		allowed bool
+		wantErr bool
+	}{
+		{"admin", f.authorizer(), EmployeePrincipal(f.admin), true, false},
+		{"member", f.authorizer(), EmployeePrincipal(f.member), false, false},
+		{"outsider", f.authorizer(), EmployeePrincipal(f.other), false, false},
+		{"api key created by an admin", f.authorizer(), ApiKeyPrincipal(f.admin, f.org), false, false},
+		{"repo error", f.failing(), EmployeePrincipal(f.admin), false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, err := tt.authz.CanManageOrganization(context.Background(), tt.p, f.org)
+			check(t, allowed, tt.allowed, err, tt.wantErr)
+		})
+	}
+}
+
+func TestCanEditEmployee(t *testing.T) {
+	f := newFixture()
+	tests := []struct {
+		name    string
+		p       Principal
+		target  uuid.UUID
+		allowed bool
+	}{
+		{"self", EmployeePrincipal(f.member), f.member, true},
+		{"admin of the target's organization", EmployeePrincipal(f.admin), f.member, false},
+		{"someone else", EmployeePrincipal(f.other), f.member, false},
+		{"api key of the employee", ApiKeyPrincipal(f.member, f.org), f.member, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, err := f.authorizer().CanEditEmployee(context.Background(), tt.p, tt.target)
+			check(t, allowed, tt.allowed, err, false)
+		})
+	}
+}
+
+func TestCanDeactivateEmployee(t *testing.T) {
+	f := newFixture()
+	tests := []struct {
+		name    string
+		authz   *Authorizer
+		p       Principal
+		target  uuid.UUID
+		allowed bool
+		wantErr bool
+	}{
+		{"self", f.authorizer(), EmployeePrincipal(f.member), f.member, true, false},
+		{"self needs no lookup", f.failing(), EmployeePrincipal(f.member), f.member, true, false},
+		{"admin of the target's organization", f.authorizer(), EmployeePrincipal(f.admin), f.member, true, false},
+		{"member of the target's organization", f.authorizer(), EmployeePrincipal(f.creator), f.member, false, false},
+		{"admin of another organization", f.authorizer(), EmployeePrincipal(f.admin), f.bidder, false, false},
+		{"api key created by the target", f.authorizer(), ApiKeyPrincipal(f.member, f.org), f.member, false, false},
+		{"api key created by an admin", f.authorizer(), ApiKeyPrincipal(f.admin, f.org), f.member, false, false},
+		{"target without organization", f.authorizer(), EmployeePrincipal(f.admin), f.other, false, false},
+		{"repo error", f.failing(), EmployeePrincipal(f.admin), f.member, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, err := tt.authz.CanDeactivateEmployee(context.Background(), tt.p, tt.target)
+			check(t, allowed, tt.allowed, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package etag
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"strconv"
+	"strings"
+)
+
+const (
+	HeaderETag    = "ETag"
+	HeaderIfMatch = "If-Match"
+)
+
+var (
+	ErrInvalidIfMatch = fmt.Errorf("invalid If-Match header")
+	ErrTagMismatch    = fmt.Errorf("If-Match does not match the resource")
+)
+
+// Format builds the entity tag of a tender or bid version.
+func Format(id uuid.UUID, version int) string {
+	return fmt.Sprintf(`"%s-%d"`, id, version)
+}
+
+func Set(c echo.Context, id uuid.UUID, version int) {
+	c.Response().Header().Set(HeaderETag, Format(id, version))
+}
+
+// ExpectedVersion returns the version the client sent in If-Match, or 0 when the
+// header is absent or "*".
+func ExpectedVersion(c echo.Context, id uuid.UUID) (int, error) {
+	header := strings.TrimSpace(c.Request().Header.Get(HeaderIfMatch))
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+	tag := strings.Trim(strings.TrimPrefix(header, "W/"), `"`)
+	sep := strings.LastIndex(tag, "-")
+	if sep < 0 {
+		return 0, ErrInvalidIfMatch
+	}
+	tagId, err := uuid.Parse(tag[:sep])
+	if err != nil {
+		return 0, ErrInvalidIfMatch
+	}
+	version, err := strconv.Atoi(tag[sep+1:])
+	if err != nil || version < 1 {
+		return 0, ErrInvalidIfMatch
+	}
+	if tagId != id {
+		return 0, ErrTagMismatch
+	}
+	return version, nil
+}
@@ -1,3 +1,14 @@
 package formating
 
+import "time"
+
 const TimeFormat = "2006-01-02T15:04:05Z07:00"
+
+// OptionalTime formats t, keeping nil for times that are not set.
+func OptionalTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	s := t.Format(TimeFormat)
+	return &s
+}
@@ -0,0 +1,64 @@
+package tenders
+
+import (
+	"avito/internal/entity"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"net/url"
+	"time"
+)
+
+// cursorToken is the JSON payload behind the opaque cursor handed to clients.
+type cursorToken struct {
+	Sort      string    `json:"s"`
+	Name      string    `json:"n,omitempty"`
+	CreatedAt time.Time `json:"c,omitempty"`
+	Id        uuid.UUID `json:"i"`
+}
+
+func EncodeCursor(cursor entity.Cursor) string {
+	data, _ := json.Marshal(cursorToken{
+		Sort:      cursor.Sort,
+		Name:      cursor.Name,
+		CreatedAt: cursor.CreatedAt,
+		Id:        cursor.Id,
+	})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// ParseCursor reads the cursor parameter. The cursor must have been issued for
+// the same sort order, and replaces offset rather than being combined with it.
+func ParseCursor(rawQuery, sort string) (*entity.Cursor, error) {
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return nil, err
+	}
+	if !values.Has("cursor") {
+		return nil, nil
+	}
+	if values.Has("offset") {
+		return nil, fmt.Errorf("cursor can not be combined with offset")
+	}
+	if sort == entity.SortByRelevance {
+		return nil, fmt.Errorf("cursor is not supported with sort=%v", sort)
+	}
+	data, err := base64.RawURLEncoding.DecodeString(values.Get("cursor"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	var token cursorToken
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	if token.Sort != sort {
+		return nil, fmt.Errorf("cursor was issued for sort=%v", token.Sort)
+	}
+	return &entity.Cursor{
+		Sort:      token.Sort,
+		Name:      token.Name,
+		CreatedAt: token.CreatedAt,
+		Id:        token.Id,
+	}, nil
+}
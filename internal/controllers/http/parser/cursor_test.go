@@ -0,0 +1,59 @@
+package tenders
+
+import (
+	"avito/internal/entity"
+	"encoding/base64"
+	"github.com/google/uuid"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursors := []entity.Cursor{
+		{Sort: entity.SortByName, Name: "Delivery of bricks", Id: uuid.New()},
+		{Sort: entity.SortByCreatedAt, CreatedAt: time.Date(2024, 9, 30, 12, 0, 0, 123456000, time.UTC), Id: uuid.New()},
+		{Sort: entity.SortByCreatedAtDesc, CreatedAt: time.Date(2024, 10, 1, 8, 30, 0, 0, time.UTC), Id: uuid.New()},
+	}
+	for _, want := range cursors {
+		t.Run(want.Sort, func(t *testing.T) {
+			rawQuery := url.Values{"cursor": {EncodeCursor(want)}}.Encode()
+			got, err := ParseCursor(rawQuery, want.Sort)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got == nil || got.Sort != want.Sort || got.Name != want.Name || !got.CreatedAt.Equal(want.CreatedAt) || got.Id != want.Id {
+				t.Fatalf("got %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestParseCursorErrors(t *testing.T) {
+	byName := EncodeCursor(entity.Cursor{Sort: entity.SortByName, Name: "a", Id: uuid.New()})
+	tests := []struct {
+		name     string
+		rawQuery string
+		sort     string
+	}{
+		{name: "combined with offset", rawQuery: "cursor=" + byName + "&offset=10", sort: entity.SortByName},
+		{name: "relevance sort", rawQuery: "cursor=" + byName, sort: entity.SortByRelevance},
+		{name: "issued for another sort", rawQuery: "cursor=" + byName, sort: entity.SortByCreatedAt},
+		{name: "not base64", rawQuery: "cursor=%21%21", sort: entity.SortByName},
+		{name: "not json", rawQuery: "cursor=" + base64.RawURLEncoding.EncodeToString([]byte("name")), sort: entity.SortByName},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if cursor, err := ParseCursor(tt.rawQuery, tt.sort); err == nil {
+				t.Fatalf("accepted cursor %+v", cursor)
+			}
+		})
+	}
+}
+
+func TestParseCursorMissing(t *testing.T) {
+	cursor, err := ParseCursor("offset=10", entity.SortByName)
+	if err != nil || cursor != nil {
+		t.Fatalf("got %+v, %v, want no cursor", cursor, err)
+	}
+}
@@ -0,0 +1,97 @@
+package tenders
+
+import (
+	"avito/internal/entity"
+	"fmt"
+	"github.com/google/uuid"
+	"net/url"
+	"slices"
+	"time"
+)
+
+// FilterOptions lists the filters and sort keys a listing accepts.
+type FilterOptions struct {
+	Statuses         []string
+	Sorts            []string
+	WithOrganization bool
+}
+
+var (
+	TenderFilter = FilterOptions{
+		Statuses:         []string{"Created", "Published", "Closed"},
+		Sorts:            []string{entity.SortByName, entity.SortByCreatedAt, entity.SortByCreatedAtDesc},
+		WithOrganization: true,
+	}
+	// TenderSearchFilter is for the public listing, which only ever shows
+	// published tenders, so other statuses are rejected instead of
+	// matching nothing.
+	TenderSearchFilter = FilterOptions{
+		Statuses:         []string{"Published"},
+		Sorts:            append(slices.Clone(TenderFilter.Sorts), entity.SortByRelevance),
+		WithOrganization: true,
+	}
+	BidFilter = FilterOptions{
+		Statuses: []string{"Created", "Published", "Canceled"},
+		Sorts:    []string{entity.SortByName, entity.SortByCreatedAt, entity.SortByCreatedAtDesc},
+	}
+)
+
+// ParseFilter reads organizationId, status, createdFrom, createdTo,
+// updatedFrom, updatedTo and sort from the query. Times are RFC 3339.
+func ParseFilter(rawQuery string, options FilterOptions) (entity.ListFilter, error) {
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return entity.ListFilter{}, err
+	}
+	filter := entity.ListFilter{
+		Sort: entity.SortByName,
+	}
+
+	if values.Has("organizationId") {
+		if !options.WithOrganization {
+			return entity.ListFilter{}, fmt.Errorf("organizationId filter is not supported")
+		}
+		organizationId, err := uuid.Parse(values.Get("organizationId"))
+		if err != nil {
+			return entity.ListFilter{}, fmt.Errorf("invalid value for organizationId: %v", err)
+		}
+		filter.OrganizationId = &organizationId
+	}
+
+	for _, status := range values["status"] {
+		if !slices.Contains(options.Statuses, status) {
+			return entity.ListFilter{}, fmt.Errorf("invalid status: %v", status)
+		}
+		filter.Statuses = append(filter.Statuses, status)
+	}
+
+	bounds := []struct {
+		name  string
+		value **time.Time
+	}{
+		{"createdFrom", &filter.CreatedFrom},
+		{"createdTo", &filter.CreatedTo},
+		{"updatedFrom", &filter.UpdatedFrom},
+		{"updatedTo", &filter.UpdatedTo},
+	}
+	for _, bound := range bounds {
+		if !values.Has(bound.name) {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, values.Get(bound.name))
+		if err != nil {
+			return entity.ListFilter{}, fmt.Errorf("invalid value for %s: %v", bound.name, err)
+		}
+		t = t.UTC()
+		*bound.value = &t
+	}
+
+	if values.Has("sort") {
+		sort := values.Get("sort")
+		if !slices.Contains(options.Sorts, sort) {
+			return entity.ListFilter{}, fmt.Errorf("invalid value for sort: %v", sort)
+		}
+		filter.Sort = sort
+	}
+	return filter, nil
+}
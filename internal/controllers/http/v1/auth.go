@@ -0,0 +1,162 @@
+package v1
+
+import (
+	"avito/internal/authz"
+	errors2 "avito/internal/controllers/http/errors"
+	"avito/internal/service"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+const (
+	identityKey  = "identity"
+	bearerPrefix = "Bearer "
+	headerApiKey = "X-API-Key"
+)
+
+// identity is the authenticated caller. Requests made with an organization API
+// key act as the employee who created the key, as AuthorType "Organization",
+// and are limited to the key's scopes and, through callerPrincipal, to the
+// key's organization.
+type identity struct {
+	EmployeeId     uuid.UUID
+	Username       string
+	ApiKey         bool
+	OrganizationId uuid.UUID
+	Scopes         []string
+}
+
+// newAuthMiddleware authenticates requests carrying an API key or a bearer token
+// and stores the caller in the echo context. Requests without credentials are
+// rejected unless usernameFallback lets handlers trust the username sent by the
+// client.
+func newAuthMiddleware(authService service.Auth, apiKeyService service.ApiKey, employeeService service.Employee, usernameFallback bool) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if key := c.Request().Header.Get(headerApiKey); key != "" {
+				apiKey, err := apiKeyService.Authenticate(c.Request().Context(), key)
+				if err != nil {
+					return errors2.NewErrorResponse(c, http.StatusUnauthorized, err)
+				}
+				employee, err := employeeService.GetEmployeeById(c.Request().Context(), apiKey.CreatedBy)
+				if err != nil {
+					return errors2.NewErrorResponse(c, http.StatusUnauthorized, err)
+				}
+				c.Set(identityKey, identity{
+					EmployeeId:     employee.Id,
+					Username:       employee.Username,
+					ApiKey:         true,
+					OrganizationId: apiKey.OrganizationId,
+					Scopes:         apiKey.Scopes,
+				})
+				return next(c)
+			}
+			header := c.Request().Header.Get(echo.HeaderAuthorization)
+			if header == "" {
+				if usernameFallback {
+					return next(c)
+				}
+				return errors2.NewErrorResponse(c, http.StatusUnauthorized, service.ErrUnauthenticated)
+			}
+			if !strings.HasPrefix(header, bearerPrefix) {
+				return errors2.NewErrorResponse(c, http.StatusUnauthorized, service.ErrInvalidToken)
+			}
+			employeeId, err := authService.ParseToken(strings.TrimPrefix(header, bearerPrefix))
+			if err != nil {
+				return errors2.NewErrorResponse(c, http.StatusUnauthorized, err)
+			}
+			employee, err := employeeService.GetEmployeeById(c.Request().Context(), employeeId)
+			if err != nil {
+				return errors2.NewErrorResponse(c, http.StatusUnauthorized, err)
+			}
+			c.Set(identityKey, identity{
+				EmployeeId: employee.Id,
+				Username:   employee.Username,
+			})
+			return next(c)
+		}
+	}
+}
+
+// requireScopes limits API key callers to the read scope for GET requests and
+// to the write scope for everything else. Employees are not affected.
+func requireScopes(readScope, writeScope string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			id, ok := getIdentity(c)
+			if !ok || !id.ApiKey {
+				return next(c)
+			}
+			scope := writeScope
+			if c.Request().Method == http.MethodGet {
+				scope = readScope
+			}
+			if !slices.Contains(id.Scopes, scope) {
+				return errors2.NewErrorResponse(c, http.StatusForbidden, service.ErrInsufficientScope)
+			}
+			return next(c)
+		}
+	}
+}
+
+// employeesOnly rejects requests authenticated with an API key.
+func employeesOnly(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if id, ok := getIdentity(c); ok && id.ApiKey {
+			return errors2.NewErrorResponse(c, http.StatusForbidden, service.ErrPermissionDenied)
+		}
+		return next(c)
+	}
+}
+
+func getIdentity(c echo.Context) (identity, bool) {
+	id, ok := c.Get(identityKey).(identity)
+	return id, ok
+}
+
+// callerUsername returns the username of the authenticated employee, or the
+// username sent by the client when the request has no token.
+func callerUsername(c echo.Context, username string) string {
+	if id, ok := getIdentity(c); ok {
+		return id.Username
+	}
+	return username
+}
+
+// callerId returns the id of the authenticated employee, or the id sent by the
+// client when the request has no token.
+func callerId(c echo.Context, employeeId uuid.UUID) uuid.UUID {
+	if id, ok := getIdentity(c); ok {
+		return id.EmployeeId
+	}
+	return employeeId
+}
+
+// callerOrganizationId forces the key's organization for API key callers, so a
+// key never acts for another organization of the employee who created it.
+func callerOrganizationId(c echo.Context, organizationId uuid.UUID) uuid.UUID {
+	if id, ok := getIdentity(c); ok && id.ApiKey {
+		return id.OrganizationId
+	}
+	return organizationId
+}
+
+// callerPrincipal is who authorization decisions are made for: the employee,
+// or the API key confined to its organization.
+func callerPrincipal(c echo.Context, employeeId uuid.UUID) authz.Principal {
+	if id, ok := getIdentity(c); ok && id.ApiKey {
+		return authz.ApiKeyPrincipal(id.EmployeeId, id.OrganizationId)
+	}
+	return authz.EmployeePrincipal(employeeId)
+}
+
+// callerAuthorType forces AuthorType "Organization" for API key callers.
+func callerAuthorType(c echo.Context, authorType string) string {
+	if id, ok := getIdentity(c); ok && id.ApiKey {
+		return "Organization"
+	}
+	return authorType
+}
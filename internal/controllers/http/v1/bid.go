@@ -1,13 +1,14 @@
 package v1
 
 import (
+	"avito/internal/authz"
 	errors2 "avito/internal/controllers/http/errors"
+	"avito/internal/controllers/http/etag"
 	"avito/internal/controllers/http/formating"
 	tenders "avito/internal/controllers/http/parser"
 	"avito/internal/controllers/validators"
 	"avito/internal/service"
 	"errors"
-	"fmt"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"net/http"
@@ -17,21 +18,28 @@ type bidRoutes struct {
 	bidService      service.Bid
 	employeeService service.Employee
 	tenderService   service.Tender
+	policy          authz.Policy
 }
 
-func newBidRoutes(g *echo.Group, bidService service.Bid, employeeService service.Employee, tenderService service.Tender) {
+func newBidRoutes(g *echo.Group, bidService service.Bid, employeeService service.Employee, tenderService service.Tender, policy authz.Policy) {
 	r := &bidRoutes{
 		bidService:      bidService,
 		employeeService: employeeService,
 		tenderService:   tenderService,
+		policy:          policy,
 	}
 	g.POST("/new", r.create)
 	g.GET("/my", r.getMyBids)
+	g.GET("/:tender_id/list", r.getBids)
 	g.GET("/:bid_id/status", r.getStatus)
 	g.PUT("/:bid_id/status", r.putStatus)
 	g.POST("/:bid_id/edit", r.editBid)
 	g.PUT("/:bid_id/rollback/:version", r.rollback)
+	g.GET("/:bid_id/versions", r.getVersions)
+	g.GET("/:bid_id/versions/:from/diff/:to", r.diffVersions)
 	g.PUT("/:bid_id/submit_decision", r.submitDecision)
+	g.PUT("/:bid_id/feedback", r.submitFeedback)
+	g.GET("/:tender_id/reviews", r.getReviews)
 }
 
 type CreateBidInput struct {
@@ -40,6 +48,10 @@ type CreateBidInput struct {
 	TenderId    uuid.UUID `json:"tenderId" validate:"required"`
 	AuthorType  string    `json:"authorType" validate:"required,oneof=User Organization"`
 	AuthorId    uuid.UUID `json:"authorId" validate:"required"`
+	// OrganizationId picks which of the author's organizations an
+	// "Organization" bid is submitted for; the bid service rejects it when
+	// missing for such bids or sent for "User" bids.
+	OrganizationId uuid.UUID `json:"organizationId"`
 }
 
 func (r *bidRoutes) create(c echo.Context) error {
@@ -52,6 +64,9 @@ func (r *bidRoutes) create(c echo.Context) error {
 		}
 		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
 	}
+	input.AuthorId = callerId(c, input.AuthorId)
+	input.AuthorType = callerAuthorType(c, input.AuthorType)
+	input.OrganizationId = callerOrganizationId(c, input.OrganizationId)
 	if err := c.Validate(input); err != nil {
 		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
 	}
@@ -59,48 +74,60 @@ func (r *bidRoutes) create(c echo.Context) error {
 	if err != nil {
 		return errors2.NewErrorResponse(c, http.StatusUnauthorized, err)
 	}
-	if input.AuthorType == "Organization" {
-		_, err := r.employeeService.GetEmployeeOrgIdById(c.Request().Context(), input.AuthorId)
-		if err != nil {
-			return errors2.NewErrorResponse(c, http.StatusForbidden, err)
-		}
+	var organizationId *uuid.UUID
+	if input.OrganizationId != uuid.Nil {
+		organizationId = &input.OrganizationId
 	}
 	bid, err := r.bidService.CreateBid(c.Request().Context(), service.BidCreateInput{
-		Name:        input.Name,
-		Description: input.Description,
-		TenderId:    input.TenderId,
-		AuthorType:  input.AuthorType,
-		AuthorId:    input.AuthorId,
+		Name:           input.Name,
+		Description:    input.Description,
+		TenderId:       input.TenderId,
+		AuthorType:     input.AuthorType,
+		AuthorId:       input.AuthorId,
+		OrganizationId: organizationId,
 	})
 	if err != nil {
+		if errors.Is(err, service.ErrTenderNotFound) {
+			return errors2.NewErrorResponse(c, http.StatusNotFound, err)
+		}
+		if errors.Is(err, service.ErrInvalidBidOrganization) {
+			return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+		}
+		if errors.Is(err, service.ErrTenderNotPublished) || errors.Is(err, service.ErrSubmissionClosed) || errors.Is(err, service.ErrOwnTender) || errors.Is(err, service.ErrOrganisationResponsibleNotFound) {
+			return errors2.NewErrorResponse(c, http.StatusForbidden, err)
+		}
 		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
 	}
 
 	type response struct {
-		Id         uuid.UUID `json:"id"`
-		Name       string    `json:"name"`
-		Status     string    `json:"status"`
-		AuthorType string    `json:"authorType"`
-		AuthorId   uuid.UUID `json:"authorId"`
-		Version    int       `json:"version"`
-		CreatedAt  string    `json:"createdAt"`
+		Id             uuid.UUID  `json:"id"`
+		Name           string     `json:"name"`
+		Status         string     `json:"status"`
+		AuthorType     string     `json:"authorType"`
+		AuthorId       uuid.UUID  `json:"authorId"`
+		OrganizationId *uuid.UUID `json:"organizationId,omitempty"`
+		Version        int        `json:"version"`
+		CreatedAt      string     `json:"createdAt"`
 	}
 
+	etag.Set(c, bid.Id, bid.Version)
 	return c.JSON(http.StatusOK, response{
-		Id:         bid.Id,
-		Name:       bid.Name,
-		Status:     bid.Status,
-		AuthorType: bid.AuthorType,
-		AuthorId:   bid.AuthorId,
-		Version:    bid.Version,
-		CreatedAt:  bid.CreatedAt.Format(formating.TimeFormat),
+		Id:             bid.Id,
+		Name:           bid.Name,
+		Status:         bid.Status,
+		AuthorType:     bid.AuthorType,
+		AuthorId:       bid.AuthorId,
+		OrganizationId: bid.OrganizationId,
+		Version:        bid.Version,
+		CreatedAt:      bid.CreatedAt.Format(formating.TimeFormat),
 	})
 }
 
 type GetMyBidsInput struct {
-	Username string `query:"username" validate:"required"`
-	Limit    int    `query:"limit"`
-	Offset   int    `query:"offset"`
+	Username  string `query:"username" validate:"required"`
+	Limit     int    `query:"limit"`
+	Offset    int    `query:"offset"`
+	WithTotal bool   `query:"withTotal"`
 }
 
 func (r *bidRoutes) getMyBids(c echo.Context) error {
@@ -109,6 +136,7 @@ func (r *bidRoutes) getMyBids(c echo.Context) error {
 		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
 	}
 
+	input.Username = callerUsername(c, input.Username)
 	if err := c.Validate(input); err != nil {
 		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
 	}
@@ -118,19 +146,29 @@ func (r *bidRoutes) getMyBids(c echo.Context) error {
 	if err != nil {
 		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
 	}
+	filter, err := tenders.ParseFilter(rawQuery, tenders.BidFilter)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	filter.After, err = tenders.ParseCursor(rawQuery, filter.Sort)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
 	authorId, err := r.employeeService.GetEmployeeIdByUsername(c.Request().Context(), input.Username)
 	if err != nil {
 		return errors2.NewErrorResponse(c, http.StatusUnauthorized, err)
 	}
-	response, err := r.bidService.GetMyBids(c.Request().Context(), service.GetMyBidsInput{
-		AuthorId: authorId,
-		Limit:    limit,
-		Offset:   offset,
+	page, err := r.bidService.GetMyBids(c.Request().Context(), service.GetMyBidsInput{
+		AuthorId:  authorId,
+		Filter:    filter,
+		Limit:     limit,
+		Offset:    offset,
+		WithTotal: input.WithTotal,
 	})
 	if err != nil {
 		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
 	}
-	return c.JSON(http.StatusOK, response)
+	return respondList(c, page.Bids, page.Total, page.Next, limit, offset)
 }
 
 type GetBidsInput struct {
@@ -140,6 +178,53 @@ type GetBidsInput struct {
 	Offset   int       `query:"offset"`
 }
 
+func (r *bidRoutes) getBids(c echo.Context) error {
+	var input GetBidsInput
+	if err := c.Bind(&input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	input.Username = callerUsername(c, input.Username)
+	if err := c.Validate(input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	rawQuery := c.Request().URL.RawQuery
+	limit, offset, err := tenders.ParseLimitOffset(rawQuery)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	filter, err := tenders.ParseFilter(rawQuery, tenders.BidFilter)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	employeeId, err := r.employeeService.GetEmployeeIdByUsername(c.Request().Context(), input.Username)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusUnauthorized, err)
+	}
+	tender, err := r.tenderService.GetTenderById(c.Request().Context(), input.TenderId)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusNotFound, err)
+	}
+	isResponsible, err := r.policy.CanEditTender(c.Request().Context(), callerPrincipal(c, employeeId), tender)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	response, err := r.bidService.GetBidsForTender(c.Request().Context(), service.GetBidsForTenderInput{
+		TenderId:      input.TenderId,
+		EmployeeId:    employeeId,
+		IsResponsible: isResponsible,
+		Filter:        filter,
+		Limit:         limit,
+		Offset:        offset,
+	})
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
 type GetStatusInput struct {
 	BidId    uuid.UUID `param:"bid_id" validate:"required"`
 	Username string    `query:"username" validate:"required"`
@@ -151,6 +236,7 @@ func (r *bidRoutes) getStatus(c echo.Context) error {
 		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
 	}
 
+	input.Username = callerUsername(c, input.Username)
 	if err := c.Validate(input); err != nil {
 		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
 	}
@@ -164,24 +250,12 @@ func (r *bidRoutes) getStatus(c echo.Context) error {
 	if err != nil {
 		return errors2.NewErrorResponse(c, http.StatusUnauthorized, err)
 	}
-	switch bid.AuthorType {
-	case "User":
-		if bid.AuthorId != employeeId {
-			return errors2.NewErrorResponse(c, http.StatusForbidden, fmt.Errorf("permission denied"))
-		}
-	case "Organization":
-		organizationEmployeeId, err := r.employeeService.GetEmployeeOrgIdById(c.Request().Context(), employeeId)
-		if err != nil {
-			return errors2.NewErrorResponse(c, http.StatusForbidden, fmt.Errorf("permission denied"))
-		}
-		organizationAuthorId, err := r.employeeService.GetEmployeeOrgIdById(c.Request().Context(), bid.AuthorId)
-		if err != nil {
-			return errors2.NewErrorResponse(c, http.StatusForbidden, fmt.Errorf("permission denied"))
-		}
-		if organizationAuthorId != organizationEmployeeId {
-			fmt.Println(employeeId)
-			return errors2.NewErrorResponse(c, http.StatusForbidden, fmt.Errorf("permission denied"))
-		}
+	allowed, err := r.policy.CanViewBid(c.Request().Context(), callerPrincipal(c, employeeId), bid)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	if !allowed {
+		return errors2.NewErrorResponse(c, http.StatusForbidden, service.ErrPermissionDenied)
 	}
 
 	response, err := r.bidService.GetStatus(c.Request().Context(), service.GetBidStatusInput{
@@ -193,6 +267,7 @@ func (r *bidRoutes) getStatus(c echo.Context) error {
 			return errors2.NewErrorResponse(c, http.StatusNotFound, err)
 		}
 	}
+	etag.Set(c, bid.Id, bid.Version)
 	return c.JSON(http.StatusOK, response)
 }
 
@@ -211,6 +286,7 @@ func (r *bidRoutes) putStatus(c echo.Context) error {
 	if err := b.BindQueryParams(c, &input); err != nil {
 		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
 	}
+	input.Username = callerUsername(c, input.Username)
 	if err := c.Validate(input); err != nil {
 		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
 	}
@@ -225,67 +301,69 @@ func (r *bidRoutes) putStatus(c echo.Context) error {
 		return errors2.NewErrorResponse(c, http.StatusUnauthorized, err)
 	}
 
-	switch bid.AuthorType {
-	case "User":
-		if bid.AuthorId != employeeId {
-			return errors2.NewErrorResponse(c, http.StatusForbidden, service.ErrPermissionDenied)
-		}
-	case "Organization":
-		organizationEmployeeId, err := r.employeeService.GetEmployeeOrgIdById(c.Request().Context(), employeeId)
-		if err != nil {
-			return errors2.NewErrorResponse(c, http.StatusForbidden, service.ErrPermissionDenied)
-		}
-		organizationAuthorId, err := r.employeeService.GetEmployeeOrgIdById(c.Request().Context(), bid.AuthorId)
-		if err != nil {
-			return errors2.NewErrorResponse(c, http.StatusForbidden, service.ErrPermissionDenied)
-		}
-		if organizationAuthorId != organizationEmployeeId {
-			fmt.Println(employeeId)
-			return errors2.NewErrorResponse(c, http.StatusForbidden, service.ErrPermissionDenied)
-		}
+	allowed, err := r.policy.CanEditBid(c.Request().Context(), callerPrincipal(c, employeeId), bid)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	if !allowed {
+		return errors2.NewErrorResponse(c, http.StatusForbidden, service.ErrPermissionDenied)
 	}
 
 	output, err := r.bidService.PutStatus(c.Request().Context(), service.PutBidStatusInput{
-		BidId:  input.BidId,
-		Status: input.Status,
+		BidId:    input.BidId,
+		Status:   input.Status,
+		EditedBy: employeeId,
 	})
 
 	if err != nil {
-		if errors.Is(err, service.ErrTenderNotFound) {
+		if errors.Is(err, service.ErrBidNotFound) {
 			return errors2.NewErrorResponse(c, http.StatusNotFound, err)
 		}
 
 		if errors.Is(err, service.ErrPermissionDenied) {
 			return errors2.NewErrorResponse(c, http.StatusForbidden, err)
 		}
+		if errors.Is(err, service.ErrVersionConflict) {
+			return errors2.NewErrorResponse(c, http.StatusConflict, err)
+		}
+		if errors.Is(err, service.ErrInvalidTransition) {
+			return errors2.NewErrorResponse(c, http.StatusConflict, err)
+		}
 		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
 	}
 	type response struct {
-		Id         uuid.UUID `json:"id"`
-		Name       string    `json:"name"`
-		Status     string    `json:"status"`
-		AuthorType string    `json:"authorType"`
-		AuthorId   uuid.UUID `json:"authorId"`
-		Version    int       `json:"version"`
-		CreatedAt  string    `json:"createdAt"`
-	}
-
+		Id           uuid.UUID  `json:"id"`
+		Name         string     `json:"name"`
+		Status       string     `json:"status"`
+		AuthorType   string     `json:"authorType"`
+		AuthorId     uuid.UUID  `json:"authorId"`
+		Version      int        `json:"version"`
+		CreatedAt    string     `json:"createdAt"`
+		EditedBy     *uuid.UUID `json:"editedBy"`
+		ChangeReason *string    `json:"changeReason"`
+	}
+
+	etag.Set(c, output.Id, output.Version)
 	return c.JSON(http.StatusOK, response{
-		Id:         output.Id,
-		Name:       output.Name,
-		Status:     output.Status,
-		AuthorType: output.AuthorType,
-		AuthorId:   output.AuthorId,
-		Version:    output.Version,
-		CreatedAt:  output.CreatedAt.Format(formating.TimeFormat),
+		Id:           output.Id,
+		Name:         output.Name,
+		Status:       output.Status,
+		AuthorType:   output.AuthorType,
+		AuthorId:     output.AuthorId,
+		Version:      output.Version,
+		CreatedAt:    output.CreatedAt.Format(formating.TimeFormat),
+		EditedBy:     output.EditedBy,
+		ChangeReason: output.ChangeReason,
 	})
 }
 
 type EditBidInput struct {
-	BidId       uuid.UUID `param:"bid_id"`
-	Username    string    `query:"username" validate:"required"`
-	Name        *string   `json:"name" validate:"omitempty"`
-	Description *string   `json:"description" validate:"omitempty"`
+	BidId           uuid.UUID `param:"bid_id"`
+	Username        string    `query:"username" validate:"required"`
+	Name            *string   `json:"name" validate:"omitempty"`
+	Description     *string   `json:"description" validate:"omitempty"`
+	ExpectedVersion *int      `json:"expectedVersion" validate:"omitempty,gte=1"`
+	ChangeReason    string    `json:"changeReason" validate:"max=500"`
 }
 
 func (r *bidRoutes) editBid(c echo.Context) error {
@@ -297,6 +375,7 @@ func (r *bidRoutes) editBid(c echo.Context) error {
 	if err := b.BindQueryParams(c, &input); err != nil {
 		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
 	}
+	input.Username = callerUsername(c, input.Username)
 	if err := c.Validate(input); err != nil {
 		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
 	}
@@ -313,24 +392,22 @@ func (r *bidRoutes) editBid(c echo.Context) error {
 		return errors2.NewErrorResponse(c, http.StatusUnauthorized, err)
 	}
 
-	switch bid.AuthorType {
-	case "User":
-		if bid.AuthorId != employeeId {
-			return errors2.NewErrorResponse(c, http.StatusForbidden, service.ErrPermissionDenied)
-		}
-	case "Organization":
-		organizationEmployeeId, err := r.employeeService.GetEmployeeOrgIdById(c.Request().Context(), employeeId)
-		if err != nil {
-			return errors2.NewErrorResponse(c, http.StatusForbidden, service.ErrPermissionDenied)
-		}
-		organizationAuthorId, err := r.employeeService.GetEmployeeOrgIdById(c.Request().Context(), bid.AuthorId)
-		if err != nil {
-			return errors2.NewErrorResponse(c, http.StatusForbidden, service.ErrPermissionDenied)
-		}
-		if organizationAuthorId != organizationEmployeeId {
-			fmt.Println(employeeId)
-			return errors2.NewErrorResponse(c, http.StatusForbidden, service.ErrPermissionDenied)
+	allowed, err := r.policy.CanEditBid(c.Request().Context(), callerPrincipal(c, employeeId), bid)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	if !allowed {
+		return errors2.NewErrorResponse(c, http.StatusForbidden, service.ErrPermissionDenied)
+	}
+	expectedVersion, err := etag.ExpectedVersion(c, input.BidId)
+	if err != nil {
+		if errors.Is(err, etag.ErrTagMismatch) {
+			return errors2.NewErrorResponse(c, http.StatusConflict, err)
 		}
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	if expectedVersion == 0 && input.ExpectedVersion != nil {
+		expectedVersion = *input.ExpectedVersion
 	}
 	var inputName, inputDescription string
 	if input.Name != nil {
@@ -340,45 +417,63 @@ func (r *bidRoutes) editBid(c echo.Context) error {
 		inputDescription = *input.Description
 	}
 	output, err := r.bidService.EditBid(c.Request().Context(), service.EditBidInput{
-		Id:          input.BidId,
-		Name:        inputName,
-		Description: inputDescription,
+		Id:              input.BidId,
+		ExpectedVersion: expectedVersion,
+		Name:            inputName,
+		Description:     inputDescription,
+		EditedBy:        employeeId,
+		Reason:          input.ChangeReason,
 	})
 	if err != nil {
-		if errors.Is(err, service.ErrTenderNotFound) {
+		if errors.Is(err, service.ErrTenderNotFound) || errors.Is(err, service.ErrBidNotFound) {
 			return errors2.NewErrorResponse(c, http.StatusNotFound, err)
 		}
+		if errors.Is(err, service.ErrTenderNotPublished) || errors.Is(err, service.ErrSubmissionClosed) || errors.Is(err, service.ErrOwnTender) {
+			return errors2.NewErrorResponse(c, http.StatusForbidden, err)
+		}
 
 		if errors.Is(err, service.ErrPermissionDenied) {
 			return errors2.NewErrorResponse(c, http.StatusForbidden, err)
 		}
+		if errors.Is(err, service.ErrVersionMismatch) {
+			return errors2.NewErrorResponse(c, http.StatusConflict, err)
+		}
+		if errors.Is(err, service.ErrVersionConflict) {
+			return errors2.NewErrorResponse(c, http.StatusConflict, err)
+		}
 		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
 	}
 	type response struct {
-		Id         uuid.UUID `json:"id"`
-		Name       string    `json:"name"`
-		Status     string    `json:"status"`
-		AuthorType string    `json:"authorType"`
-		AuthorId   uuid.UUID `json:"authorId"`
-		Version    int       `json:"version"`
-		CreatedAt  string    `json:"createdAt"`
-	}
-
+		Id           uuid.UUID  `json:"id"`
+		Name         string     `json:"name"`
+		Status       string     `json:"status"`
+		AuthorType   string     `json:"authorType"`
+		AuthorId     uuid.UUID  `json:"authorId"`
+		Version      int        `json:"version"`
+		CreatedAt    string     `json:"createdAt"`
+		EditedBy     *uuid.UUID `json:"editedBy"`
+		ChangeReason *string    `json:"changeReason"`
+	}
+
+	etag.Set(c, output.Id, output.Version)
 	return c.JSON(http.StatusOK, response{
-		Id:         output.Id,
-		Name:       output.Name,
-		Status:     output.Status,
-		AuthorType: output.AuthorType,
-		AuthorId:   output.AuthorId,
-		Version:    output.Version,
-		CreatedAt:  output.CreatedAt.Format(formating.TimeFormat),
+		Id:           output.Id,
+		Name:         output.Name,
+		Status:       output.Status,
+		AuthorType:   output.AuthorType,
+		AuthorId:     output.AuthorId,
+		Version:      output.Version,
+		CreatedAt:    output.CreatedAt.Format(formating.TimeFormat),
+		EditedBy:     output.EditedBy,
+		ChangeReason: output.ChangeReason,
 	})
 }
 
 type RollbackBidInput struct {
-	BidId    uuid.UUID `param:"bid_id" validate:"required"`
-	Version  int       `param:"version" validate:"required,gte=1"`
-	Username string    `query:"username" validate:"required"`
+	BidId        uuid.UUID `param:"bid_id" validate:"required"`
+	Version      int       `param:"version" validate:"required,gte=1"`
+	Username     string    `query:"username" validate:"required"`
+	ChangeReason string    `query:"changeReason" validate:"max=500"`
 }
 
 func (r *bidRoutes) rollback(c echo.Context) error {
@@ -390,6 +485,7 @@ func (r *bidRoutes) rollback(c echo.Context) error {
 	if err := b.BindQueryParams(c, &input); err != nil {
 		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
 	}
+	input.Username = callerUsername(c, input.Username)
 	if err := c.Validate(input); err != nil {
 		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
 	}
@@ -404,28 +500,18 @@ func (r *bidRoutes) rollback(c echo.Context) error {
 		return errors2.NewErrorResponse(c, http.StatusUnauthorized, err)
 	}
 
-	switch bid.AuthorType {
-	case "User":
-		if bid.AuthorId != employeeId {
-			return errors2.NewErrorResponse(c, http.StatusForbidden, service.ErrPermissionDenied)
-		}
-	case "Organization":
-		organizationEmployeeId, err := r.employeeService.GetEmployeeOrgIdById(c.Request().Context(), employeeId)
-		if err != nil {
-			return errors2.NewErrorResponse(c, http.StatusForbidden, service.ErrPermissionDenied)
-		}
-		organizationAuthorId, err := r.employeeService.GetEmployeeOrgIdById(c.Request().Context(), bid.AuthorId)
-		if err != nil {
-			return errors2.NewErrorResponse(c, http.StatusForbidden, service.ErrPermissionDenied)
-		}
-		if organizationAuthorId != organizationEmployeeId {
-			fmt.Println(employeeId)
-			return errors2.NewErrorResponse(c, http.StatusForbidden, service.ErrPermissionDenied)
-		}
+	allowed, err := r.policy.CanEditBid(c.Request().Context(), callerPrincipal(c, employeeId), bid)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	if !allowed {
+		return errors2.NewErrorResponse(c, http.StatusForbidden, service.ErrPermissionDenied)
 	}
 	output, err := r.bidService.RollbackVersion(c.Request().Context(), service.RollbackVersionInput{
-		Id:      input.BidId,
-		Version: input.Version,
+		Id:       input.BidId,
+		Version:  input.Version,
+		EditedBy: employeeId,
+		Reason:   input.ChangeReason,
 	})
 	if err != nil {
 		if errors.Is(err, service.ErrPermissionDenied) {
@@ -437,8 +523,102 @@ func (r *bidRoutes) rollback(c echo.Context) error {
 		if errors.Is(err, service.ErrBidNotFound) {
 			return errors2.NewErrorResponse(c, http.StatusNotFound, err)
 		}
+		if errors.Is(err, service.ErrVersionConflict) {
+			return errors2.NewErrorResponse(c, http.StatusConflict, err)
+		}
+		if errors.Is(err, service.ErrInvalidTransition) {
+			return errors2.NewErrorResponse(c, http.StatusConflict, err)
+		}
+		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	type response struct {
+		Id           uuid.UUID  `json:"id"`
+		Name         string     `json:"name"`
+		Status       string     `json:"status"`
+		AuthorType   string     `json:"authorType"`
+		AuthorId     uuid.UUID  `json:"authorId"`
+		Version      int        `json:"version"`
+		CreatedAt    string     `json:"createdAt"`
+		EditedBy     *uuid.UUID `json:"editedBy"`
+		ChangeReason *string    `json:"changeReason"`
+	}
+
+	etag.Set(c, output.Id, output.Version)
+	return c.JSON(http.StatusOK, response{
+		Id:           output.Id,
+		Name:         output.Name,
+		Status:       output.Status,
+		AuthorType:   output.AuthorType,
+		AuthorId:     output.AuthorId,
+		Version:      output.Version,
+		CreatedAt:    output.CreatedAt.Format(formating.TimeFormat),
+		EditedBy:     output.EditedBy,
+		ChangeReason: output.ChangeReason,
+	})
+}
+
+type SubmitDecisionInput struct {
+	BidId    uuid.UUID `param:"bid_id" validate:"required"`
+	Decision string    `query:"decision" validate:"required,oneof=Approved Rejected"`
+	Username string    `query:"username" validate:"required"`
+}
+
+func (r *bidRoutes) submitDecision(c echo.Context) error {
+	var input SubmitDecisionInput
+	if err := c.Bind(&input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	b := echo.DefaultBinder{}
+	if err := b.BindQueryParams(c, &input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	input.Username = callerUsername(c, input.Username)
+	if err := c.Validate(input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	bid, err := r.bidService.GetBidById(c.Request().Context(), input.BidId)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusNotFound, err)
+	}
+
+	employeeId, err := r.employeeService.GetEmployeeIdByUsername(c.Request().Context(), input.Username)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusUnauthorized, err)
+	}
+	tender, err := r.tenderService.GetTenderById(c.Request().Context(), bid.TenderId)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusNotFound, err)
+	}
+
+	allowed, err := r.policy.CanDecide(c.Request().Context(), callerPrincipal(c, employeeId), tender)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	if !allowed {
+		return errors2.NewErrorResponse(c, http.StatusForbidden, service.ErrPermissionDenied)
+	}
+	output, err := r.bidService.SubmitDecision(c.Request().Context(), service.SubmitDecisionInput{
+		TenderId:   bid.TenderId,
+		BidId:      bid.Id,
+		EmployeeId: employeeId,
+		Decision:   input.Decision,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrBidNotFound) || errors.Is(err, service.ErrTenderNotFound) {
+			return errors2.NewErrorResponse(c, http.StatusNotFound, err)
+		}
+		if errors.Is(err, service.ErrTenderClosed) ||
+			errors.Is(err, service.ErrBidAlreadyDecided) ||
+			errors.Is(err, service.ErrDecisionAlreadySubmitted) {
+			return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+		}
+		if errors.Is(err, service.ErrInvalidTransition) {
+			return errors2.NewErrorResponse(c, http.StatusConflict, err)
+		}
 		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
 	}
+
 	type response struct {
 		Id         uuid.UUID `json:"id"`
 		Name       string    `json:"name"`
@@ -449,6 +629,7 @@ func (r *bidRoutes) rollback(c echo.Context) error {
 		CreatedAt  string    `json:"createdAt"`
 	}
 
+	etag.Set(c, output.Id, output.Version)
 	return c.JSON(http.StatusOK, response{
 		Id:         output.Id,
 		Name:       output.Name,
@@ -460,14 +641,14 @@ func (r *bidRoutes) rollback(c echo.Context) error {
 	})
 }
 
-type SubmitDecisionInput struct {
-	BidId    uuid.UUID `param:"bid_id" validate:"required"`
-	Decision string    `query:"decision" validate:"required,oneof=Approved Rejected"`
-	Username string    `query:"username" validate:"required"`
+type SubmitFeedbackInput struct {
+	BidId       uuid.UUID `param:"bid_id" validate:"required"`
+	BidFeedback string    `query:"bidFeedback" validate:"required,max=1000"`
+	Username    string    `query:"username" validate:"required"`
 }
 
-func (r *bidRoutes) submitDecision(c echo.Context) error {
-	var input SubmitDecisionInput
+func (r *bidRoutes) submitFeedback(c echo.Context) error {
+	var input SubmitFeedbackInput
 	if err := c.Bind(&input); err != nil {
 		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
 	}
@@ -475,6 +656,7 @@ func (r *bidRoutes) submitDecision(c echo.Context) error {
 	if err := b.BindQueryParams(c, &input); err != nil {
 		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
 	}
+	input.Username = callerUsername(c, input.Username)
 	if err := c.Validate(input); err != nil {
 		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
 	}
@@ -493,15 +675,29 @@ func (r *bidRoutes) submitDecision(c echo.Context) error {
 		return errors2.NewErrorResponse(c, http.StatusNotFound, err)
 	}
 
-	employeeOrg, err := r.employeeService.GetEmployeeOrgIdById(c.Request().Context(), employeeId)
+	allowed, err := r.policy.CanDecide(c.Request().Context(), callerPrincipal(c, employeeId), tender)
 	if err != nil {
-		return errors2.NewErrorResponse(c, http.StatusForbidden, err)
+		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
 	}
-	if employeeOrg != tender.OrganizationId {
-		return errors2.NewErrorResponse(c, http.StatusForbidden, err)
+	if !allowed {
+		return errors2.NewErrorResponse(c, http.StatusForbidden, service.ErrPermissionDenied)
 	}
-	output, err := r.bidService.SubmitDecision(c.Request().Context(), bid.TenderId, bid.Id, input.Decision)
+	output, err := r.bidService.SubmitFeedback(c.Request().Context(), service.SubmitFeedbackInput{
+		TenderId:   bid.TenderId,
+		BidId:      bid.Id,
+		EmployeeId: employeeId,
+		Feedback:   input.BidFeedback,
+	})
 	if err != nil {
+		if errors.Is(err, service.ErrBidNotFound) || errors.Is(err, service.ErrTenderNotFound) {
+			return errors2.NewErrorResponse(c, http.StatusNotFound, err)
+		}
+		if errors.Is(err, service.ErrCannotSubmitFeedback) {
+			return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+		}
+		if errors.Is(err, service.ErrInvalidTransition) {
+			return errors2.NewErrorResponse(c, http.StatusConflict, err)
+		}
 		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
 	}
 
@@ -515,6 +711,7 @@ func (r *bidRoutes) submitDecision(c echo.Context) error {
 		CreatedAt  string    `json:"createdAt"`
 	}
 
+	etag.Set(c, output.Id, output.Version)
 	return c.JSON(http.StatusOK, response{
 		Id:         output.Id,
 		Name:       output.Name,
@@ -525,3 +722,150 @@ func (r *bidRoutes) submitDecision(c echo.Context) error {
 		CreatedAt:  output.CreatedAt.Format(formating.TimeFormat),
 	})
 }
+
+type GetReviewsInput struct {
+	TenderId          uuid.UUID `param:"tender_id" validate:"required"`
+	AuthorUsername    string    `query:"authorUsername" validate:"required"`
+	RequesterUsername string    `query:"requesterUsername" validate:"required"`
+	Limit             int       `query:"limit"`
+	Offset            int       `query:"offset"`
+}
+
+func (r *bidRoutes) getReviews(c echo.Context) error {
+	var input GetReviewsInput
+	if err := c.Bind(&input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	input.RequesterUsername = callerUsername(c, input.RequesterUsername)
+	if err := c.Validate(input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	rawQuery := c.Request().URL.RawQuery
+	limit, offset, err := tenders.ParseLimitOffset(rawQuery)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	requesterId, err := r.employeeService.GetEmployeeIdByUsername(c.Request().Context(), input.RequesterUsername)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusUnauthorized, err)
+	}
+	tender, err := r.tenderService.GetTenderById(c.Request().Context(), input.TenderId)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusNotFound, err)
+	}
+	allowed, err := r.policy.CanDecide(c.Request().Context(), callerPrincipal(c, requesterId), tender)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	if !allowed {
+		return errors2.NewErrorResponse(c, http.StatusForbidden, service.ErrPermissionDenied)
+	}
+	authorId, err := r.employeeService.GetEmployeeIdByUsername(c.Request().Context(), input.AuthorUsername)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusNotFound, err)
+	}
+
+	response, err := r.bidService.GetReviews(c.Request().Context(), service.GetReviewsInput{
+		TenderId: tender.Id,
+		AuthorId: authorId,
+		Limit:    limit,
+		Offset:   offset,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrAuthorBidNotFound) {
+			return errors2.NewErrorResponse(c, http.StatusNotFound, err)
+		}
+		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+type GetBidVersionsInput struct {
+	BidId    uuid.UUID `param:"bid_id" validate:"required"`
+	Username string    `query:"username" validate:"required"`
+}
+
+func (r *bidRoutes) getVersions(c echo.Context) error {
+	var input GetBidVersionsInput
+	if err := c.Bind(&input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	input.Username = callerUsername(c, input.Username)
+	if err := c.Validate(input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	bid, err := r.bidService.GetBidById(c.Request().Context(), input.BidId)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusNotFound, err)
+	}
+	employeeId, err := r.employeeService.GetEmployeeIdByUsername(c.Request().Context(), input.Username)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusUnauthorized, err)
+	}
+	allowed, err := r.policy.CanViewBid(c.Request().Context(), callerPrincipal(c, employeeId), bid)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	if !allowed {
+		return errors2.NewErrorResponse(c, http.StatusForbidden, service.ErrPermissionDenied)
+	}
+
+	versions, err := r.bidService.GetVersions(c.Request().Context(), input.BidId)
+	if err != nil {
+		if errors.Is(err, service.ErrBidNotFound) {
+			return errors2.NewErrorResponse(c, http.StatusNotFound, err)
+		}
+		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	return c.JSON(http.StatusOK, versions)
+}
+
+type DiffBidVersionsInput struct {
+	BidId    uuid.UUID `param:"bid_id" validate:"required"`
+	From     int       `param:"from" validate:"required,gte=1"`
+	To       int       `param:"to" validate:"required,gte=1"`
+	Username string    `query:"username" validate:"required"`
+}
+
+func (r *bidRoutes) diffVersions(c echo.Context) error {
+	var input DiffBidVersionsInput
+	if err := c.Bind(&input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	input.Username = callerUsername(c, input.Username)
+	if err := c.Validate(input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	bid, err := r.bidService.GetBidById(c.Request().Context(), input.BidId)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusNotFound, err)
+	}
+	employeeId, err := r.employeeService.GetEmployeeIdByUsername(c.Request().Context(), input.Username)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusUnauthorized, err)
+	}
+	allowed, err := r.policy.CanViewBid(c.Request().Context(), callerPrincipal(c, employeeId), bid)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	if !allowed {
+		return errors2.NewErrorResponse(c, http.StatusForbidden, service.ErrPermissionDenied)
+	}
+
+	diff, err := r.bidService.DiffVersions(c.Request().Context(), service.DiffVersionsInput{
+		Id:   input.BidId,
+		From: input.From,
+		To:   input.To,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrVersionNotFound) {
+			return errors2.NewErrorResponse(c, http.StatusNotFound, err)
+		}
+		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	return c.JSON(http.StatusOK, diff)
+}
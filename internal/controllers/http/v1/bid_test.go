@@ -0,0 +1,124 @@
+package v1
+
+import (
+	"avito/internal/authz"
+	"avito/internal/controllers/validators"
+	"avito/internal/entity"
+	"avito/internal/repo"
+	"avito/internal/service"
+	"context"
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type authorBidKey struct {
+	tenderId, authorId uuid.UUID
+}
+
+// fakeBidRepo knows which authors have bids on which tenders and serves the
+// feedback on every author's bids from a map.
+type fakeBidRepo struct {
+	repo.Bid
+	authorBids map[authorBidKey]bool
+	hasBidErr  error
+	feedback   map[uuid.UUID][]entity.BidFeedback
+}
+
+func (f *fakeBidRepo) HasAuthorBid(_ context.Context, tenderId, authorId uuid.UUID) (bool, error) {
+	if f.hasBidErr != nil {
+		return false, f.hasBidErr
+	}
+	return f.authorBids[authorBidKey{tenderId, authorId}], nil
+}
+
+func (f *fakeBidRepo) GetAuthorFeedback(_ context.Context, authorId uuid.UUID, _, _ int) ([]entity.BidFeedback, error) {
+	return f.feedback[authorId], nil
+}
+
+// fakePolicy lets the employees in deciders decide on every tender.
+type fakePolicy struct {
+	authz.Policy
+	deciders map[uuid.UUID]bool
+}
+
+func (f *fakePolicy) CanDecide(_ context.Context, p authz.Principal, _ *entity.Tender) (bool, error) {
+	return f.deciders[p.EmployeeId], nil
+}
+
+func newBidServer(bids *fakeBidRepo, tenders *fakeTenderRepo, employees *fakeEmployeeRepo, policy authz.Policy) *echo.Echo {
+	e := echo.New()
+	e.Validator = validators.New()
+	newBidRoutes(
+		e.Group("/api/bids"),
+		service.NewBidService(bids, tenders, employees),
+		service.NewEmployeeService(employees),
+		service.NewTenderService(tenders, employees),
+		policy,
+	)
+	return e
+}
+
+func TestGetReviewsChecksAuthorBid(t *testing.T) {
+	requesterId, authorId, strangerId := uuid.New(), uuid.New(), uuid.New()
+	tender := &entity.Tender{Id: uuid.New(), Status: "Published", OrganizationId: uuid.New()}
+	otherTenderId := uuid.New()
+	review := entity.BidFeedback{Id: uuid.New(), EmployeeId: requesterId, Description: "late delivery"}
+
+	tests := []struct {
+		name      string
+		author    string
+		requester string
+		hasBidErr error
+		status    int
+	}{
+		{name: "author of a bid on the tender", author: "author", requester: "requester", status: http.StatusOK},
+		{name: "author with bids on other tenders only", author: "stranger", requester: "requester", status: http.StatusNotFound},
+		{name: "unknown author", author: "nobody", requester: "requester", status: http.StatusNotFound},
+		{name: "requester cannot decide", author: "author", requester: "author", status: http.StatusForbidden},
+		{name: "bid lookup fails", author: "author", requester: "requester", hasBidErr: errDB, status: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			employees := &fakeEmployeeRepo{
+				ids: map[string]uuid.UUID{"requester": requesterId, "author": authorId, "stranger": strangerId},
+			}
+			tenders := &fakeTenderRepo{tenders: map[uuid.UUID]*entity.Tender{tender.Id: tender}}
+			bids := &fakeBidRepo{
+				authorBids: map[authorBidKey]bool{
+					{tender.Id, authorId}:       true,
+					{otherTenderId, strangerId}: true,
+				},
+				hasBidErr: tt.hasBidErr,
+				feedback: map[uuid.UUID][]entity.BidFeedback{
+					authorId:   {review},
+					strangerId: {review},
+				},
+			}
+			policy := &fakePolicy{deciders: map[uuid.UUID]bool{requesterId: true}}
+
+			target := "/api/bids/" + tender.Id.String() + "/reviews?authorUsername=" + tt.author + "&requesterUsername=" + tt.requester
+			req := httptest.NewRequest(http.MethodGet, target, nil)
+			rec := httptest.NewRecorder()
+
+			newBidServer(bids, tenders, employees, policy).ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d, body %s", rec.Code, tt.status, rec.Body)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var got []service.GetReviewsOutput
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != 1 || got[0].Id != review.Id {
+				t.Fatalf("unexpected response %s", rec.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package v1
+
+import (
+	"avito/internal/authz"
+	errors2 "avito/internal/controllers/http/errors"
+	"avito/internal/service"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+type employeeRoutes struct {
+	employeeService service.Employee
+	policy          authz.Policy
+}
+
+func newEmployeeRoutes(g *echo.Group, employeeService service.Employee, policy authz.Policy) {
+	r := &employeeRoutes{
+		employeeService: employeeService,
+		policy:          policy,
+	}
+	g.POST("", r.create)
+	g.GET("/:employee_username", r.getEmployee)
+	g.PATCH("/:employee_username", r.edit)
+	g.PUT("/:employee_username/deactivate", r.deactivate)
+}
+
+func newProfileRoutes(g *echo.Group, employeeService service.Employee) {
+	r := &employeeRoutes{
+		employeeService: employeeService,
+	}
+	g.GET("", r.getProfile)
+}
+
+type CreateEmployeeInput struct {
+	Username    string  `query:"username" validate:"required"`
+	NewUsername string  `json:"username" validate:"required,max=50"`
+	FirstName   *string `json:"firstName" validate:"omitempty,max=50"`
+	LastName    *string `json:"lastName" validate:"omitempty,max=50"`
+}
+
+func (r *employeeRoutes) create(c echo.Context) error {
+	var input CreateEmployeeInput
+	if err := c.Bind(&input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	b := echo.DefaultBinder{}
+	if err := b.BindQueryParams(c, &input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	input.Username = callerUsername(c, input.Username)
+	if err := c.Validate(input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	if _, err := r.employeeService.GetEmployeeIdByUsername(c.Request().Context(), input.Username); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusUnauthorized, err)
+	}
+	output, err := r.employeeService.CreateEmployee(c.Request().Context(), service.CreateEmployeeInput{
+		Username:  input.NewUsername,
+		FirstName: input.FirstName,
+		LastName:  input.LastName,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrEmployeeAlreadyExists) {
+			return errors2.NewErrorResponse(c, http.StatusConflict, err)
+		}
+		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	return c.JSON(http.StatusOK, output)
+}
+
+type GetEmployeeInput struct {
+	EmployeeUsername string `param:"employee_username" validate:"required"`
+	Username         string `query:"username" validate:"required"`
+}
+
+func (r *employeeRoutes) getEmployee(c echo.Context) error {
+	var input GetEmployeeInput
+	if err := c.Bind(&input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	input.Username = callerUsername(c, input.Username)
+	if err := c.Validate(input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	if _, err := r.employeeService.GetEmployeeIdByUsername(c.Request().Context(), input.Username); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusUnauthorized, err)
+	}
+	output, err := r.employeeService.GetEmployeeByUsername(c.Request().Context(), input.EmployeeUsername)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusNotFound, err)
+	}
+	return c.JSON(http.StatusOK, output)
+}
+
+type EditEmployeeInput struct {
+	EmployeeUsername string  `param:"employee_username" validate:"required"`
+	Username         string  `query:"username" validate:"required"`
+	FirstName        *string `json:"firstName" validate:"omitempty,max=50"`
+	LastName         *string `json:"lastName" validate:"omitempty,max=50"`
+}
+
+func (r *employeeRoutes) edit(c echo.Context) error {
+	var input EditEmployeeInput
+	if err := c.Bind(&input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	b := echo.DefaultBinder{}
+	if err := b.BindQueryParams(c, &input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	input.Username = callerUsername(c, input.Username)
+	if err := c.Validate(input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	employeeId, err := r.employeeService.GetEmployeeIdByUsername(c.Request().Context(), input.Username)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusUnauthorized, err)
+	}
+	target, err := r.employeeService.GetEmployeeByUsername(c.Request().Context(), input.EmployeeUsername)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusNotFound, err)
+	}
+	allowed, err := r.policy.CanEditEmployee(c.Request().Context(), callerPrincipal(c, employeeId), target.Id)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	if !allowed {
+		return errors2.NewErrorResponse(c, http.StatusForbidden, service.ErrPermissionDenied)
+	}
+	output, err := r.employeeService.EditEmployee(c.Request().Context(), service.EditEmployeeInput{
+		Id:        target.Id,
+		FirstName: input.FirstName,
+		LastName:  input.LastName,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrEmployeeDoesNotExist) {
+			return errors2.NewErrorResponse(c, http.StatusNotFound, err)
+		}
+		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	return c.JSON(http.StatusOK, output)
+}
+
+type DeactivateEmployeeInput struct {
+	EmployeeUsername string `param:"employee_username" validate:"required"`
+	Username         string `query:"username" validate:"required"`
+}
+
+func (r *employeeRoutes) deactivate(c echo.Context) error {
+	var input DeactivateEmployeeInput
+	if err := c.Bind(&input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	b := echo.DefaultBinder{}
+	if err := b.BindQueryParams(c, &input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	input.Username = callerUsername(c, input.Username)
+	if err := c.Validate(input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	employeeId, err := r.employeeService.GetEmployeeIdByUsername(c.Request().Context(), input.Username)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusUnauthorized, err)
+	}
+	target, err := r.employeeService.GetEmployeeByUsername(c.Request().Context(), input.EmployeeUsername)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusNotFound, err)
+	}
+	allowed, err := r.policy.CanDeactivateEmployee(c.Request().Context(), callerPrincipal(c, employeeId), target.Id)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	if !allowed {
+		return errors2.NewErrorResponse(c, http.StatusForbidden, service.ErrPermissionDenied)
+	}
+	output, err := r.employeeService.DeactivateEmployee(c.Request().Context(), target.Id)
+	if err != nil {
+		if errors.Is(err, service.ErrEmployeeDoesNotExist) {
+			return errors2.NewErrorResponse(c, http.StatusNotFound, err)
+		}
+		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	return c.JSON(http.StatusOK, output)
+}
+
+type GetProfileInput struct {
+	Username string `query:"username" validate:"required"`
+}
+
+// getProfile returns the caller. API key callers act on behalf of the key's
+// organization only, so it replaces the organizations of the employee who
+// created the key.
+func (r *employeeRoutes) getProfile(c echo.Context) error {
+	var input GetProfileInput
+	if err := c.Bind(&input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	input.Username = callerUsername(c, input.Username)
+	if err := c.Validate(input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	employeeId, err := r.employeeService.GetEmployeeIdByUsername(c.Request().Context(), input.Username)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusUnauthorized, err)
+	}
+	output, err := r.employeeService.GetProfile(c.Request().Context(), employeeId)
+	if err != nil {
+		if errors.Is(err, service.ErrEmployeeDoesNotExist) {
+			return errors2.NewErrorResponse(c, http.StatusUnauthorized, err)
+		}
+		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	if id, ok := getIdentity(c); ok && id.ApiKey {
+		output.OrganizationIds = []uuid.UUID{id.OrganizationId}
+	}
+	return c.JSON(http.StatusOK, output)
+}
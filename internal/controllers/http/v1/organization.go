@@ -0,0 +1,415 @@
+package v1
+
+import (
+	"avito/internal/authz"
+	errors2 "avito/internal/controllers/http/errors"
+	tenders "avito/internal/controllers/http/parser"
+	"avito/internal/service"
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+type organizationRoutes struct {
+	organizationService service.Organization
+	apiKeyService       service.ApiKey
+	employeeService     service.Employee
+	policy              authz.Policy
+}
+
+func newOrganizationRoutes(g *echo.Group, organizationService service.Organization, apiKeyService service.ApiKey, employeeService service.Employee, policy authz.Policy) {
+	r := &organizationRoutes{
+		organizationService: organizationService,
+		apiKeyService:       apiKeyService,
+		employeeService:     employeeService,
+		policy:              policy,
+	}
+	g.POST("", r.create)
+	g.GET("", r.getOrganizations)
+	g.GET("/:organization_id", r.getOrganization)
+	g.PATCH("/:organization_id", r.edit)
+	g.DELETE("/:organization_id", r.delete)
+	g.GET("/:organization_id/responsibles", r.getResponsibles)
+	g.PUT("/:organization_id/responsibles/:user_id", r.putResponsible)
+	g.DELETE("/:organization_id/responsibles/:user_id", r.removeResponsible)
+	g.POST("/:organization_id/api_keys", r.createApiKey)
+	g.GET("/:organization_id/api_keys", r.getApiKeys)
+	g.DELETE("/:organization_id/api_keys/:key_id", r.revokeApiKey)
+}
+
+// authorizeAdmin resolves the caller and checks that they manage the
+// organization, returning the status code to respond with on failure.
+func (r *organizationRoutes) authorizeAdmin(c echo.Context, username string, organizationId uuid.UUID) (uuid.UUID, int, error) {
+	return r.authorize(c, username, organizationId, r.policy.CanManageOrganization)
+}
+
+// authorizeResponsible is authorizeAdmin for actions open to every
+// responsible of the organization.
+func (r *organizationRoutes) authorizeResponsible(c echo.Context, username string, organizationId uuid.UUID) (uuid.UUID, int, error) {
+	return r.authorize(c, username, organizationId, r.policy.CanViewOrganization)
+}
+
+func (r *organizationRoutes) authorize(c echo.Context, username string, organizationId uuid.UUID, check func(ctx context.Context, p authz.Principal, organizationId uuid.UUID) (bool, error)) (uuid.UUID, int, error) {
+	employeeId, err := r.employeeService.GetEmployeeIdByUsername(c.Request().Context(), username)
+	if err != nil {
+		return uuid.Nil, http.StatusUnauthorized, err
+	}
+	allowed, err := check(c.Request().Context(), callerPrincipal(c, employeeId), organizationId)
+	if err != nil {
+		return uuid.Nil, http.StatusInternalServerError, err
+	}
+	if !allowed {
+		return uuid.Nil, http.StatusForbidden, service.ErrPermissionDenied
+	}
+	return employeeId, http.StatusOK, nil
+}
+
+type CreateOrganizationInput struct {
+	Username    string  `query:"username" validate:"required"`
+	Name        string  `json:"name" validate:"required,max=100"`
+	Description *string `json:"description"`
+	Type        *string `json:"type" validate:"omitempty,oneof=IE LLC JSC"`
+}
+
+func (r *organizationRoutes) create(c echo.Context) error {
+	var input CreateOrganizationInput
+	if err := c.Bind(&input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	b := echo.DefaultBinder{}
+	if err := b.BindQueryParams(c, &input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	input.Username = callerUsername(c, input.Username)
+	if err := c.Validate(input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	employeeId, err := r.employeeService.GetEmployeeIdByUsername(c.Request().Context(), input.Username)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusUnauthorized, err)
+	}
+	output, err := r.organizationService.CreateOrganization(c.Request().Context(), service.CreateOrganizationInput{
+		Name:        input.Name,
+		Description: input.Description,
+		Type:        input.Type,
+		CreatedBy:   employeeId,
+	})
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	return c.JSON(http.StatusOK, output)
+}
+
+type GetOrganizationsInput struct {
+	Username string `query:"username" validate:"required"`
+	Limit    int    `query:"limit"`
+	Offset   int    `query:"offset"`
+}
+
+func (r *organizationRoutes) getOrganizations(c echo.Context) error {
+	var input GetOrganizationsInput
+	if err := c.Bind(&input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	input.Username = callerUsername(c, input.Username)
+	if err := c.Validate(input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	limit, offset, err := tenders.ParseLimitOffset(c.Request().URL.RawQuery)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	if _, err := r.employeeService.GetEmployeeIdByUsername(c.Request().Context(), input.Username); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusUnauthorized, err)
+	}
+	response, err := r.organizationService.GetOrganizations(c.Request().Context(), limit, offset)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+type GetOrganizationInput struct {
+	OrganizationId uuid.UUID `param:"organization_id" validate:"required"`
+	Username       string    `query:"username" validate:"required"`
+}
+
+func (r *organizationRoutes) getOrganization(c echo.Context) error {
+	var input GetOrganizationInput
+	if err := c.Bind(&input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	input.Username = callerUsername(c, input.Username)
+	if err := c.Validate(input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	if _, err := r.employeeService.GetEmployeeIdByUsername(c.Request().Context(), input.Username); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusUnauthorized, err)
+	}
+	output, err := r.organizationService.GetOrganizationById(c.Request().Context(), input.OrganizationId)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusNotFound, err)
+	}
+	return c.JSON(http.StatusOK, output)
+}
+
+type EditOrganizationInput struct {
+	OrganizationId uuid.UUID `param:"organization_id" validate:"required"`
+	Username       string    `query:"username" validate:"required"`
+	Name           *string   `json:"name" validate:"omitempty,min=1,max=100"`
+	Description    *string   `json:"description"`
+	Type           *string   `json:"type" validate:"omitempty,oneof=IE LLC JSC"`
+}
+
+func (r *organizationRoutes) edit(c echo.Context) error {
+	var input EditOrganizationInput
+	if err := c.Bind(&input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	b := echo.DefaultBinder{}
+	if err := b.BindQueryParams(c, &input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	input.Username = callerUsername(c, input.Username)
+	if err := c.Validate(input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	_, status, err := r.authorizeAdmin(c, input.Username, input.OrganizationId)
+	if err != nil {
+		return errors2.NewErrorResponse(c, status, err)
+	}
+	output, err := r.organizationService.EditOrganization(c.Request().Context(), service.EditOrganizationInput{
+		Id:          input.OrganizationId,
+		Name:        input.Name,
+		Description: input.Description,
+		Type:        input.Type,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrOrganizationNotFound) {
+			return errors2.NewErrorResponse(c, http.StatusNotFound, err)
+		}
+		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	return c.JSON(http.StatusOK, output)
+}
+
+type DeleteOrganizationInput struct {
+	OrganizationId uuid.UUID `param:"organization_id" validate:"required"`
+	Username       string    `query:"username" validate:"required"`
+}
+
+func (r *organizationRoutes) delete(c echo.Context) error {
+	var input DeleteOrganizationInput
+	if err := c.Bind(&input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	input.Username = callerUsername(c, input.Username)
+	if err := c.Validate(input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	_, status, err := r.authorizeAdmin(c, input.Username, input.OrganizationId)
+	if err != nil {
+		return errors2.NewErrorResponse(c, status, err)
+	}
+	if err := r.organizationService.DeleteOrganization(c.Request().Context(), input.OrganizationId); err != nil {
+		if errors.Is(err, service.ErrOrganizationNotFound) {
+			return errors2.NewErrorResponse(c, http.StatusNotFound, err)
+		}
+		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+type GetResponsiblesInput struct {
+	OrganizationId uuid.UUID `param:"organization_id" validate:"required"`
+	Username       string    `query:"username" validate:"required"`
+}
+
+func (r *organizationRoutes) getResponsibles(c echo.Context) error {
+	var input GetResponsiblesInput
+	if err := c.Bind(&input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	input.Username = callerUsername(c, input.Username)
+	if err := c.Validate(input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	_, status, err := r.authorizeResponsible(c, input.Username, input.OrganizationId)
+	if err != nil {
+		return errors2.NewErrorResponse(c, status, err)
+	}
+	response, err := r.organizationService.GetResponsibles(c.Request().Context(), input.OrganizationId)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+type PutResponsibleInput struct {
+	OrganizationId uuid.UUID `param:"organization_id" validate:"required"`
+	UserId         uuid.UUID `param:"user_id" validate:"required"`
+	Username       string    `query:"username" validate:"required"`
+	Role           string    `json:"role" validate:"required,oneof=Admin Member"`
+}
+
+func (r *organizationRoutes) putResponsible(c echo.Context) error {
+	var input PutResponsibleInput
+	if err := c.Bind(&input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	b := echo.DefaultBinder{}
+	if err := b.BindQueryParams(c, &input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	input.Username = callerUsername(c, input.Username)
+	if err := c.Validate(input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	_, status, err := r.authorizeAdmin(c, input.Username, input.OrganizationId)
+	if err != nil {
+		return errors2.NewErrorResponse(c, status, err)
+	}
+	output, err := r.organizationService.PutResponsible(c.Request().Context(), service.PutResponsibleInput{
+		OrganizationId: input.OrganizationId,
+		UserId:         input.UserId,
+		Role:           input.Role,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrEmployeeDoesNotExist):
+			return errors2.NewErrorResponse(c, http.StatusNotFound, err)
+		case errors.Is(err, service.ErrLastAdmin):
+			return errors2.NewErrorResponse(c, http.StatusConflict, err)
+		}
+		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	return c.JSON(http.StatusOK, output)
+}
+
+type RemoveResponsibleInput struct {
+	OrganizationId uuid.UUID `param:"organization_id" validate:"required"`
+	UserId         uuid.UUID `param:"user_id" validate:"required"`
+	Username       string    `query:"username" validate:"required"`
+}
+
+func (r *organizationRoutes) removeResponsible(c echo.Context) error {
+	var input RemoveResponsibleInput
+	if err := c.Bind(&input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	input.Username = callerUsername(c, input.Username)
+	if err := c.Validate(input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	_, status, err := r.authorizeAdmin(c, input.Username, input.OrganizationId)
+	if err != nil {
+		return errors2.NewErrorResponse(c, status, err)
+	}
+	if err := r.organizationService.RemoveResponsible(c.Request().Context(), input.OrganizationId, input.UserId); err != nil {
+		switch {
+		case errors.Is(err, service.ErrResponsibleNotFound):
+			return errors2.NewErrorResponse(c, http.StatusNotFound, err)
+		case errors.Is(err, service.ErrLastAdmin):
+			return errors2.NewErrorResponse(c, http.StatusConflict, err)
+		}
+		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+type CreateApiKeyInput struct {
+	OrganizationId uuid.UUID `param:"organization_id" validate:"required"`
+	Username       string    `query:"username" validate:"required"`
+	Scopes         []string  `json:"scopes" validate:"required,min=1,dive,oneof=tenders:read tenders:write bids:read bids:write"`
+}
+
+func (r *organizationRoutes) createApiKey(c echo.Context) error {
+	var input CreateApiKeyInput
+	if err := c.Bind(&input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	b := echo.DefaultBinder{}
+	if err := b.BindQueryParams(c, &input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	input.Username = callerUsername(c, input.Username)
+	if err := c.Validate(input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	employeeId, status, err := r.authorizeAdmin(c, input.Username, input.OrganizationId)
+	if err != nil {
+		return errors2.NewErrorResponse(c, status, err)
+	}
+	output, err := r.apiKeyService.CreateApiKey(c.Request().Context(), service.CreateApiKeyInput{
+		OrganizationId: input.OrganizationId,
+		CreatedBy:      employeeId,
+		Scopes:         input.Scopes,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidScope) {
+			return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+		}
+		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	return c.JSON(http.StatusOK, output)
+}
+
+type GetApiKeysInput struct {
+	OrganizationId uuid.UUID `param:"organization_id" validate:"required"`
+	Username       string    `query:"username" validate:"required"`
+}
+
+func (r *organizationRoutes) getApiKeys(c echo.Context) error {
+	var input GetApiKeysInput
+	if err := c.Bind(&input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	input.Username = callerUsername(c, input.Username)
+	if err := c.Validate(input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	_, status, err := r.authorizeAdmin(c, input.Username, input.OrganizationId)
+	if err != nil {
+		return errors2.NewErrorResponse(c, status, err)
+	}
+	response, err := r.apiKeyService.GetApiKeys(c.Request().Context(), input.OrganizationId)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+type RevokeApiKeyInput struct {
+	OrganizationId uuid.UUID `param:"organization_id" validate:"required"`
+	KeyId          uuid.UUID `param:"key_id" validate:"required"`
+	Username       string    `query:"username" validate:"required"`
+}
+
+func (r *organizationRoutes) revokeApiKey(c echo.Context) error {
+	var input RevokeApiKeyInput
+	if err := c.Bind(&input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	b := echo.DefaultBinder{}
+	if err := b.BindQueryParams(c, &input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	input.Username = callerUsername(c, input.Username)
+	if err := c.Validate(input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	_, status, err := r.authorizeAdmin(c, input.Username, input.OrganizationId)
+	if err != nil {
+		return errors2.NewErrorResponse(c, status, err)
+	}
+	output, err := r.apiKeyService.RevokeApiKey(c.Request().Context(), input.OrganizationId, input.KeyId)
+	if err != nil {
+		if errors.Is(err, service.ErrApiKeyNotFound) {
+			return errors2.NewErrorResponse(c, http.StatusNotFound, err)
+		}
+		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	return c.JSON(http.StatusOK, output)
+}
@@ -0,0 +1,48 @@
+package v1
+
+import (
+	tenders "avito/internal/controllers/http/parser"
+	"avito/internal/entity"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+)
+
+const (
+	// nextCursorHeader carries the cursor of the next page, so list responses
+	// keep their plain array bodies.
+	nextCursorHeader = "X-Next-Cursor"
+	totalCountHeader = "X-Total-Count"
+)
+
+// listEnvelope is the body of a list response requested with withTotal=true.
+type listEnvelope struct {
+	Items      any     `json:"items"`
+	Total      int     `json:"total"`
+	Limit      int     `json:"limit"`
+	Offset     int     `json:"offset"`
+	NextCursor *string `json:"nextCursor"`
+}
+
+// respondList writes a page of a listing. The items are sent as a bare array
+// unless the total was counted, in which case they are wrapped in a
+// listEnvelope.
+func respondList(c echo.Context, items any, total *int, next *entity.Cursor, limit, offset int) error {
+	var nextCursor *string
+	if next != nil {
+		cursor := tenders.EncodeCursor(*next)
+		nextCursor = &cursor
+		c.Response().Header().Set(nextCursorHeader, cursor)
+	}
+	if total == nil {
+		return c.JSON(http.StatusOK, items)
+	}
+	c.Response().Header().Set(totalCountHeader, strconv.Itoa(*total))
+	return c.JSON(http.StatusOK, listEnvelope{
+		Items:      items,
+		Total:      *total,
+		Limit:      limit,
+		Offset:     offset,
+		NextCursor: nextCursor,
+	})
+}
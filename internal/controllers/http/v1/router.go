@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"avito/internal/authz"
 	"avito/internal/service"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
@@ -9,7 +10,7 @@ import (
 	"os"
 )
 
-func NewRouter(handler *echo.Echo, services *service.Services) {
+func NewRouter(handler *echo.Echo, services *service.Services, policy authz.Policy, usernameFallback bool) {
 	handler.Use(middleware.LoggerWithConfig(middleware.LoggerConfig{
 		Format: `{"time":"${time_rfc3339_nano}", "method":"${method}","uri":"${uri}", "status":${status},"error":"${error}"}` + "\n",
 		Output: setLogsFile(),
@@ -19,8 +20,12 @@ func NewRouter(handler *echo.Echo, services *service.Services) {
 	v1 := handler.Group("/api")
 	{
 		v1.GET("/ping", func(c echo.Context) error { return c.String(http.StatusOK, "ok") })
-		newTenderRoutes(v1.Group("/tenders"), services.Tender, services.Employee)
-		newBidRoutes(v1.Group("/bids"), services.Bid, services.Employee, services.Tender)
+		auth := newAuthMiddleware(services.Auth, services.ApiKey, services.Employee, usernameFallback)
+		newTenderRoutes(v1.Group("/tenders", auth, requireScopes(service.ScopeTendersRead, service.ScopeTendersWrite)), services.Tender, services.Employee, policy)
+		newBidRoutes(v1.Group("/bids", auth, requireScopes(service.ScopeBidsRead, service.ScopeBidsWrite)), services.Bid, services.Employee, services.Tender, policy)
+		newOrganizationRoutes(v1.Group("/organizations", auth, employeesOnly), services.Organization, services.ApiKey, services.Employee, policy)
+		newEmployeeRoutes(v1.Group("/employees", auth, employeesOnly), services.Employee, policy)
+		newProfileRoutes(v1.Group("/me", auth), services.Employee)
 	}
 }
 
@@ -1,7 +1,9 @@
 package v1
 
 import (
+	"avito/internal/authz"
 	errors2 "avito/internal/controllers/http/errors"
+	"avito/internal/controllers/http/etag"
 	"avito/internal/controllers/http/formating"
 	tenders "avito/internal/controllers/http/parser"
 	"avito/internal/controllers/validators"
@@ -10,25 +12,32 @@ import (
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"net/http"
+	"strings"
+	"time"
 )
 
 type tenderRoutes struct {
 	tenderService   service.Tender
 	employeeService service.Employee
+	policy          authz.Policy
 }
 
-func newTenderRoutes(g *echo.Group, tenderService service.Tender, employeeService service.Employee) {
+func newTenderRoutes(g *echo.Group, tenderService service.Tender, employeeService service.Employee, policy authz.Policy) {
 	r := &tenderRoutes{
 		tenderService:   tenderService,
 		employeeService: employeeService,
+		policy:          policy,
 	}
 	g.POST("/new", r.create)
 	g.GET("/my", r.getMyTenders)
 	g.GET("", r.getTenders)
+	g.GET("/:tender_id", r.getTender)
 	g.GET("/:tender_id/status", r.getStatus)
 	g.PUT("/:tender_id/status", r.putStatus)
 	g.PATCH("/:tender_id/edit", r.editTender)
 	g.PUT("/:tender_id/rollback/:version", r.rollback)
+	g.GET("/:tender_id/versions", r.getVersions)
+	g.GET("/:tender_id/versions/:from/diff/:to", r.diffVersions)
 }
 
 type TenderCreationInput struct {
@@ -37,6 +46,9 @@ type TenderCreationInput struct {
 	ServiceType     string    `json:"serviceType" validate:"required,oneof=Construction Delivery Manufacture"`
 	OrganizationId  uuid.UUID `json:"organizationId" validate:"required"`
 	CreatorUsername string    `json:"creatorUsername" validate:"required,max=50"`
+	// the deadlines are RFC 3339 timestamps
+	SubmissionDeadline *time.Time `json:"submissionDeadline"`
+	DecisionDeadline   *time.Time `json:"decisionDeadline"`
 }
 
 func (r *tenderRoutes) create(c echo.Context) error {
@@ -49,6 +61,8 @@ func (r *tenderRoutes) create(c echo.Context) error {
 		}
 		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
 	}
+	input.CreatorUsername = callerUsername(c, input.CreatorUsername)
+	input.OrganizationId = callerOrganizationId(c, input.OrganizationId)
 	if err := c.Validate(input); err != nil {
 		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
 	}
@@ -56,48 +70,59 @@ func (r *tenderRoutes) create(c echo.Context) error {
 	if err != nil {
 		return errors2.NewErrorResponse(c, http.StatusUnauthorized, err)
 	}
-	_, err = r.employeeService.GetEmployeeOrgIdById(c.Request().Context(), employeeId)
-	if err != nil {
-		return errors2.NewErrorResponse(c, http.StatusForbidden, err)
-	}
 	tender, err := r.tenderService.CreateTender(c.Request().Context(), service.TenderCreateInput{
-		Name:            input.Name,
-		Description:     input.Description,
-		ServiceType:     input.ServiceType,
-		OrganizationId:  input.OrganizationId,
-		CreatorUsername: input.CreatorUsername,
+		Name:               input.Name,
+		Description:        input.Description,
+		ServiceType:        input.ServiceType,
+		OrganizationId:     input.OrganizationId,
+		CreatorUsername:    input.CreatorUsername,
+		CreatorId:          employeeId,
+		SubmissionDeadline: input.SubmissionDeadline,
+		DecisionDeadline:   input.DecisionDeadline,
 	})
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidDeadline) {
+			return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+		}
+		if errors.Is(err, service.ErrPermissionDenied) {
+			return errors2.NewErrorResponse(c, http.StatusForbidden, err)
+		}
 		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
 	}
 
 	type response struct {
-		Id             uuid.UUID `json:"id"`
-		Name           string    `json:"name"`
-		Description    string    `json:"description"`
-		Status         string    `json:"status"`
-		ServiceType    string    `json:"serviceType"`
-		Version        int       `json:"version"`
-		OrganizationId uuid.UUID `json:"organization_id"`
-		CreatedAt      string    `json:"createdAt"`
+		Id                 uuid.UUID `json:"id"`
+		Name               string    `json:"name"`
+		Description        string    `json:"description"`
+		Status             string    `json:"status"`
+		ServiceType        string    `json:"serviceType"`
+		Version            int       `json:"version"`
+		OrganizationId     uuid.UUID `json:"organization_id"`
+		CreatedAt          string    `json:"createdAt"`
+		SubmissionDeadline *string   `json:"submissionDeadline"`
+		DecisionDeadline   *string   `json:"decisionDeadline"`
 	}
 
+	etag.Set(c, tender.Id, tender.Version)
 	return c.JSON(http.StatusOK, response{
-		Id:             tender.Id,
-		Name:           tender.Name,
-		Description:    tender.Description,
-		Status:         tender.Status,
-		ServiceType:    tender.Type,
-		OrganizationId: tender.OrganizationId,
-		Version:        tender.Version,
-		CreatedAt:      tender.CreatedAt.Format(formating.TimeFormat),
+		Id:                 tender.Id,
+		Name:               tender.Name,
+		Description:        tender.Description,
+		Status:             tender.Status,
+		ServiceType:        tender.Type,
+		OrganizationId:     tender.OrganizationId,
+		Version:            tender.Version,
+		CreatedAt:          tender.CreatedAt.Format(formating.TimeFormat),
+		SubmissionDeadline: formating.OptionalTime(tender.SubmissionDeadline),
+		DecisionDeadline:   formating.OptionalTime(tender.DecisionDeadline),
 	})
 }
 
 type GetMyTendersInput struct {
-	Username string `query:"username" validate:"required"`
-	Limit    int    `query:"limit"`
-	Offset   int    `query:"offset"`
+	Username  string `query:"username" validate:"required"`
+	Limit     int    `query:"limit"`
+	Offset    int    `query:"offset"`
+	WithTotal bool   `query:"withTotal"`
 }
 
 func (r *tenderRoutes) getMyTenders(c echo.Context) error {
@@ -106,6 +131,7 @@ func (r *tenderRoutes) getMyTenders(c echo.Context) error {
 		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
 	}
 
+	input.Username = callerUsername(c, input.Username)
 	if err := c.Validate(input); err != nil {
 		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
 	}
@@ -115,25 +141,37 @@ func (r *tenderRoutes) getMyTenders(c echo.Context) error {
 	if err != nil {
 		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
 	}
+	filter, err := tenders.ParseFilter(rawQuery, tenders.TenderFilter)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	filter.After, err = tenders.ParseCursor(rawQuery, filter.Sort)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
 	_, err = r.employeeService.GetEmployeeIdByUsername(c.Request().Context(), input.Username)
 	if err != nil {
 		return errors2.NewErrorResponse(c, http.StatusUnauthorized, err)
 	}
-	response, err := r.tenderService.GetMyTenders(c.Request().Context(), service.GetMyTendersInput{
-		Username: input.Username,
-		Limit:    limit,
-		Offset:   offset,
+	page, err := r.tenderService.GetMyTenders(c.Request().Context(), service.GetMyTendersInput{
+		Username:  input.Username,
+		Filter:    filter,
+		Limit:     limit,
+		Offset:    offset,
+		WithTotal: input.WithTotal,
 	})
 	if err != nil {
 		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
 	}
-	return c.JSON(http.StatusOK, response)
+	return respondList(c, page.Tenders, page.Total, page.Next, limit, offset)
 }
 
 type GetTendersInput struct {
 	ServiceTypes []string `query:"service_type"`
+	Q            string   `query:"q" validate:"max=200"`
 	Limit        int      `query:"limit"`
 	Offset       int      `query:"offset"`
+	WithTotal    bool     `query:"withTotal"`
 }
 
 func (r *tenderRoutes) getTenders(c echo.Context) error {
@@ -150,15 +188,102 @@ func (r *tenderRoutes) getTenders(c echo.Context) error {
 	if err != nil {
 		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
 	}
-	response, err := r.tenderService.GetTenders(c.Request().Context(), service.GetTendersInput{
+	filter, err := tenders.ParseFilter(rawQuery, tenders.TenderSearchFilter)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	filter.After, err = tenders.ParseCursor(rawQuery, filter.Sort)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	input.Q = strings.TrimSpace(input.Q)
+	if err := validators.SearchTendersValidate(input.Q, filter.Sort); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	page, err := r.tenderService.GetTenders(c.Request().Context(), service.GetTendersInput{
 		ServiceTypes: serviceTypes,
+		Query:        input.Q,
+		Filter:       filter,
 		Limit:        limit,
 		Offset:       offset,
+		WithTotal:    input.WithTotal,
 	})
 	if err != nil {
 		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
 	}
-	return c.JSON(http.StatusOK, response)
+	return respondList(c, page.Tenders, page.Total, page.Next, limit, offset)
+}
+
+type GetTenderInput struct {
+	TenderId uuid.UUID `param:"tender_id" validate:"required"`
+	Username string    `query:"username" validate:"required"`
+}
+
+func (r *tenderRoutes) getTender(c echo.Context) error {
+	var input GetTenderInput
+	if err := c.Bind(&input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	input.Username = callerUsername(c, input.Username)
+	if err := c.Validate(input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	employeeId, err := r.employeeService.GetEmployeeIdByUsername(c.Request().Context(), input.Username)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusUnauthorized, err)
+	}
+	tender, err := r.tenderService.GetTenderById(c.Request().Context(), input.TenderId)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusNotFound, err)
+	}
+	allowed, err := r.policy.CanViewTender(c.Request().Context(), callerPrincipal(c, employeeId), tender)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	if !allowed {
+		return errors2.NewErrorResponse(c, http.StatusForbidden, service.ErrPermissionDenied)
+	}
+
+	type response struct {
+		Id                 uuid.UUID  `json:"id"`
+		Name               string     `json:"name"`
+		Description        string     `json:"description"`
+		Status             string     `json:"status"`
+		ServiceType        string     `json:"serviceType"`
+		OrganizationId     uuid.UUID  `json:"organizationId"`
+		CreatorUsername    string     `json:"creatorUsername"`
+		Version            int        `json:"version"`
+		VersionsCount      int        `json:"versionsCount"`
+		CreatedAt          string     `json:"createdAt"`
+		UpdatedAt          string     `json:"updatedAt"`
+		EditedBy           *uuid.UUID `json:"editedBy"`
+		ChangeReason       *string    `json:"changeReason"`
+		SubmissionDeadline *string    `json:"submissionDeadline"`
+		DecisionDeadline   *string    `json:"decisionDeadline"`
+	}
+
+	etag.Set(c, tender.Id, tender.Version)
+	return c.JSON(http.StatusOK, response{
+		Id:              tender.Id,
+		Name:            tender.Name,
+		Description:     tender.Description,
+		Status:          tender.Status,
+		ServiceType:     tender.Type,
+		OrganizationId:  tender.OrganizationId,
+		CreatorUsername: tender.CreatorUsername,
+		Version:         tender.Version,
+		// every change inserts latest+1 and versions are never deleted, so the
+		// latest version number is also the number of versions
+		VersionsCount:      tender.Version,
+		CreatedAt:          tender.CreatedAt.Format(formating.TimeFormat),
+		UpdatedAt:          tender.UpdatedAt.Format(formating.TimeFormat),
+		EditedBy:           tender.EditedBy,
+		ChangeReason:       tender.ChangeReason,
+		SubmissionDeadline: formating.OptionalTime(tender.SubmissionDeadline),
+		DecisionDeadline:   formating.OptionalTime(tender.DecisionDeadline),
+	})
 }
 
 type getStatusInput struct {
@@ -172,14 +297,26 @@ func (r *tenderRoutes) getStatus(c echo.Context) error {
 		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
 	}
 
+	input.Username = callerUsername(c, input.Username)
 	if err := c.Validate(input); err != nil {
 		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
 	}
 
-	_, err := r.employeeService.GetEmployeeIdByUsername(c.Request().Context(), input.Username)
+	employeeId, err := r.employeeService.GetEmployeeIdByUsername(c.Request().Context(), input.Username)
 	if err != nil {
 		return errors2.NewErrorResponse(c, http.StatusUnauthorized, err)
 	}
+	current, err := r.tenderService.GetTenderById(c.Request().Context(), input.TenderId)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusNotFound, err)
+	}
+	allowed, err := r.policy.CanViewTender(c.Request().Context(), callerPrincipal(c, employeeId), current)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	if !allowed {
+		return errors2.NewErrorResponse(c, http.StatusForbidden, service.ErrPermissionDenied)
+	}
 
 	response, err := r.tenderService.GetStatus(c.Request().Context(), service.GetStatusInput{
 		TenderId: input.TenderId,
@@ -195,6 +332,7 @@ func (r *tenderRoutes) getStatus(c echo.Context) error {
 		}
 		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
 	}
+	etag.Set(c, current.Id, current.Version)
 	return c.JSON(http.StatusOK, response)
 }
 
@@ -213,17 +351,30 @@ func (r *tenderRoutes) putStatus(c echo.Context) error {
 	if err := b.BindQueryParams(c, &input); err != nil {
 		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
 	}
+	input.Username = callerUsername(c, input.Username)
 	if err := c.Validate(input); err != nil {
 		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
 	}
-	_, err := r.employeeService.GetEmployeeIdByUsername(c.Request().Context(), input.Username)
+	employeeId, err := r.employeeService.GetEmployeeIdByUsername(c.Request().Context(), input.Username)
 	if err != nil {
 		return errors2.NewErrorResponse(c, http.StatusUnauthorized, err)
 	}
+	current, err := r.tenderService.GetTenderById(c.Request().Context(), input.TenderId)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusNotFound, err)
+	}
+	allowed, err := r.policy.CanEditTender(c.Request().Context(), callerPrincipal(c, employeeId), current)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	if !allowed {
+		return errors2.NewErrorResponse(c, http.StatusForbidden, service.ErrPermissionDenied)
+	}
 	tender, err := r.tenderService.PutStatus(c.Request().Context(), service.PutStatusInput{
 		TenderId: input.TenderId,
 		Username: input.Username,
 		Status:   input.Status,
+		EditedBy: employeeId,
 	})
 	if err != nil {
 		if errors.Is(err, service.ErrTenderNotFound) {
@@ -233,34 +384,49 @@ func (r *tenderRoutes) putStatus(c echo.Context) error {
 		if errors.Is(err, service.ErrPermissionDenied) {
 			return errors2.NewErrorResponse(c, http.StatusForbidden, err)
 		}
+		if errors.Is(err, service.ErrVersionConflict) {
+			return errors2.NewErrorResponse(c, http.StatusConflict, err)
+		}
+		if errors.Is(err, service.ErrInvalidTransition) {
+			return errors2.NewErrorResponse(c, http.StatusConflict, err)
+		}
 		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
 	}
 	type response struct {
-		Id          uuid.UUID `json:"id"`
-		Name        string    `json:"name"`
-		Description string    `json:"description"`
-		Status      string    `json:"status"`
-		ServiceType string    `json:"serviceType"`
-		Version     int       `json:"version"`
-		CreatedAt   string    `json:"createdAt"`
-	}
+		Id           uuid.UUID  `json:"id"`
+		Name         string     `json:"name"`
+		Description  string     `json:"description"`
+		Status       string     `json:"status"`
+		ServiceType  string     `json:"serviceType"`
+		Version      int        `json:"version"`
+		CreatedAt    string     `json:"createdAt"`
+		EditedBy     *uuid.UUID `json:"editedBy"`
+		ChangeReason *string    `json:"changeReason"`
+	}
+	etag.Set(c, tender.Id, tender.Version)
 	return c.JSON(http.StatusOK, response{
-		Id:          tender.Id,
-		Name:        tender.Name,
-		Description: tender.Description,
-		Status:      tender.Status,
-		ServiceType: tender.ServiceType,
-		Version:     tender.Version,
-		CreatedAt:   tender.CreatedAt.Format(formating.TimeFormat),
+		Id:           tender.Id,
+		Name:         tender.Name,
+		Description:  tender.Description,
+		Status:       tender.Status,
+		ServiceType:  tender.ServiceType,
+		Version:      tender.Version,
+		CreatedAt:    tender.CreatedAt.Format(formating.TimeFormat),
+		EditedBy:     tender.EditedBy,
+		ChangeReason: tender.ChangeReason,
 	})
 }
 
 type EditTenderInput struct {
-	TenderId    uuid.UUID `param:"tender_id"`
-	Username    string    `query:"username" validate:"required"`
-	Name        *string   `json:"name" validate:"omitempty"`
-	Description *string   `json:"description" validate:"omitempty"`
-	ServiceType *string   `json:"service_type" validate:"omitempty,oneof=Construction Delivery Manufacture"`
+	TenderId           uuid.UUID  `param:"tender_id"`
+	Username           string     `query:"username" validate:"required"`
+	Name               *string    `json:"name" validate:"omitempty"`
+	Description        *string    `json:"description" validate:"omitempty"`
+	ServiceType        *string    `json:"service_type" validate:"omitempty,oneof=Construction Delivery Manufacture"`
+	ExpectedVersion    *int       `json:"expectedVersion" validate:"omitempty,gte=1"`
+	ChangeReason       string     `json:"changeReason" validate:"max=500"`
+	SubmissionDeadline *time.Time `json:"submissionDeadline"`
+	DecisionDeadline   *time.Time `json:"decisionDeadline"`
 }
 
 func (r *tenderRoutes) editTender(c echo.Context) error {
@@ -272,16 +438,38 @@ func (r *tenderRoutes) editTender(c echo.Context) error {
 	if err := b.BindQueryParams(c, &input); err != nil {
 		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
 	}
+	input.Username = callerUsername(c, input.Username)
 	if err := c.Validate(input); err != nil {
 		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
 	}
 	if err := validators.EditTenderValidate(input.Name, input.Description); err != nil {
 		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
 	}
-	_, err := r.employeeService.GetEmployeeIdByUsername(c.Request().Context(), input.Username)
+	employeeId, err := r.employeeService.GetEmployeeIdByUsername(c.Request().Context(), input.Username)
 	if err != nil {
 		return errors2.NewErrorResponse(c, http.StatusUnauthorized, err)
 	}
+	current, err := r.tenderService.GetTenderById(c.Request().Context(), input.TenderId)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusNotFound, err)
+	}
+	allowed, err := r.policy.CanEditTender(c.Request().Context(), callerPrincipal(c, employeeId), current)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	if !allowed {
+		return errors2.NewErrorResponse(c, http.StatusForbidden, service.ErrPermissionDenied)
+	}
+	expectedVersion, err := etag.ExpectedVersion(c, input.TenderId)
+	if err != nil {
+		if errors.Is(err, etag.ErrTagMismatch) {
+			return errors2.NewErrorResponse(c, http.StatusConflict, err)
+		}
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	if expectedVersion == 0 && input.ExpectedVersion != nil {
+		expectedVersion = *input.ExpectedVersion
+	}
 	var inputName, inputDescription, inputServiceType string
 	if input.Name != nil {
 		inputName = *input.Name
@@ -293,45 +481,69 @@ func (r *tenderRoutes) editTender(c echo.Context) error {
 		inputServiceType = *input.ServiceType
 	}
 	tender, err := r.tenderService.EditTender(c.Request().Context(), service.EditTenderInput{
-		Id:          input.TenderId,
-		Name:        inputName,
-		Description: inputDescription,
-		ServiceType: inputServiceType,
+		Id:                 input.TenderId,
+		ExpectedVersion:    expectedVersion,
+		Name:               inputName,
+		Description:        inputDescription,
+		ServiceType:        inputServiceType,
+		EditedBy:           employeeId,
+		Reason:             input.ChangeReason,
+		SubmissionDeadline: input.SubmissionDeadline,
+		DecisionDeadline:   input.DecisionDeadline,
 	})
 	if err != nil {
 		if errors.Is(err, service.ErrTenderNotFound) {
 			return errors2.NewErrorResponse(c, http.StatusNotFound, err)
 		}
+		if errors.Is(err, service.ErrInvalidDeadline) {
+			return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+		}
 
 		if errors.Is(err, service.ErrPermissionDenied) {
 			return errors2.NewErrorResponse(c, http.StatusForbidden, err)
 		}
+		if errors.Is(err, service.ErrVersionMismatch) {
+			return errors2.NewErrorResponse(c, http.StatusConflict, err)
+		}
+		if errors.Is(err, service.ErrVersionConflict) {
+			return errors2.NewErrorResponse(c, http.StatusConflict, err)
+		}
 		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
 	}
 	type response struct {
-		Id          uuid.UUID `json:"id"`
-		Name        string    `json:"name"`
-		Description string    `json:"description"`
-		Status      string    `json:"status"`
-		ServiceType string    `json:"serviceType"`
-		Version     int       `json:"version"`
-		CreatedAt   string    `json:"createdAt"`
-	}
+		Id                 uuid.UUID  `json:"id"`
+		Name               string     `json:"name"`
+		Description        string     `json:"description"`
+		Status             string     `json:"status"`
+		ServiceType        string     `json:"serviceType"`
+		Version            int        `json:"version"`
+		CreatedAt          string     `json:"createdAt"`
+		EditedBy           *uuid.UUID `json:"editedBy"`
+		ChangeReason       *string    `json:"changeReason"`
+		SubmissionDeadline *string    `json:"submissionDeadline"`
+		DecisionDeadline   *string    `json:"decisionDeadline"`
+	}
+	etag.Set(c, tender.Id, tender.Version)
 	return c.JSON(http.StatusOK, response{
-		Id:          tender.Id,
-		Name:        tender.Name,
-		Description: tender.Description,
-		Status:      tender.Status,
-		ServiceType: tender.ServiceType,
-		Version:     tender.Version,
-		CreatedAt:   tender.CreatedAt.Format(formating.TimeFormat),
+		Id:                 tender.Id,
+		Name:               tender.Name,
+		Description:        tender.Description,
+		Status:             tender.Status,
+		ServiceType:        tender.ServiceType,
+		Version:            tender.Version,
+		CreatedAt:          tender.CreatedAt.Format(formating.TimeFormat),
+		EditedBy:           tender.EditedBy,
+		ChangeReason:       tender.ChangeReason,
+		SubmissionDeadline: formating.OptionalTime(tender.SubmissionDeadline),
+		DecisionDeadline:   formating.OptionalTime(tender.DecisionDeadline),
 	})
 }
 
 type RollbackInput struct {
-	TenderId uuid.UUID `param:"tender_id" validate:"required"`
-	Version  int       `param:"version" validate:"required,gte=1"`
-	Username string    `query:"username" validate:"required"`
+	TenderId     uuid.UUID `param:"tender_id" validate:"required"`
+	Version      int       `param:"version" validate:"required,gte=1"`
+	Username     string    `query:"username" validate:"required"`
+	ChangeReason string    `query:"changeReason" validate:"max=500"`
 }
 
 func (r *tenderRoutes) rollback(c echo.Context) error {
@@ -343,17 +555,31 @@ func (r *tenderRoutes) rollback(c echo.Context) error {
 	if err := b.BindQueryParams(c, &input); err != nil {
 		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
 	}
+	input.Username = callerUsername(c, input.Username)
 	if err := c.Validate(input); err != nil {
 		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
 	}
-	_, err := r.employeeService.GetEmployeeIdByUsername(c.Request().Context(), input.Username)
+	employeeId, err := r.employeeService.GetEmployeeIdByUsername(c.Request().Context(), input.Username)
 	if err != nil {
 		return errors2.NewErrorResponse(c, http.StatusUnauthorized, err)
 	}
+	current, err := r.tenderService.GetTenderById(c.Request().Context(), input.TenderId)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusNotFound, err)
+	}
+	allowed, err := r.policy.CanEditTender(c.Request().Context(), callerPrincipal(c, employeeId), current)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	if !allowed {
+		return errors2.NewErrorResponse(c, http.StatusForbidden, service.ErrPermissionDenied)
+	}
 
 	tender, err := r.tenderService.RollbackVersion(c.Request().Context(), service.RollbackVersionInput{
-		Id:      input.TenderId,
-		Version: input.Version,
+		Id:       input.TenderId,
+		Version:  input.Version,
+		EditedBy: employeeId,
+		Reason:   input.ChangeReason,
 	})
 	if err != nil {
 		if errors.Is(err, service.ErrTenderNotFound) {
@@ -365,24 +591,121 @@ func (r *tenderRoutes) rollback(c echo.Context) error {
 		if errors.Is(err, service.ErrVersionNotFound) {
 			return errors2.NewErrorResponse(c, http.StatusNotFound, err)
 		}
+		if errors.Is(err, service.ErrVersionConflict) {
+			return errors2.NewErrorResponse(c, http.StatusConflict, err)
+		}
+		if errors.Is(err, service.ErrInvalidTransition) {
+			return errors2.NewErrorResponse(c, http.StatusConflict, err)
+		}
 		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
 	}
 	type response struct {
-		Id          uuid.UUID `json:"id"`
-		Name        string    `json:"name"`
-		Description string    `json:"description"`
-		Status      string    `json:"status"`
-		ServiceType string    `json:"serviceType"`
-		Version     int       `json:"version"`
-		CreatedAt   string    `json:"createdAt"`
-	}
+		Id           uuid.UUID  `json:"id"`
+		Name         string     `json:"name"`
+		Description  string     `json:"description"`
+		Status       string     `json:"status"`
+		ServiceType  string     `json:"serviceType"`
+		Version      int        `json:"version"`
+		CreatedAt    string     `json:"createdAt"`
+		EditedBy     *uuid.UUID `json:"editedBy"`
+		ChangeReason *string    `json:"changeReason"`
+	}
+	etag.Set(c, tender.Id, tender.Version)
 	return c.JSON(http.StatusOK, response{
-		Id:          tender.Id,
-		Name:        tender.Name,
-		Description: tender.Description,
-		Status:      tender.Status,
-		ServiceType: tender.ServiceType,
-		Version:     tender.Version,
-		CreatedAt:   tender.CreatedAt.Format(formating.TimeFormat),
+		Id:           tender.Id,
+		Name:         tender.Name,
+		Description:  tender.Description,
+		Status:       tender.Status,
+		ServiceType:  tender.ServiceType,
+		Version:      tender.Version,
+		CreatedAt:    tender.CreatedAt.Format(formating.TimeFormat),
+		EditedBy:     tender.EditedBy,
+		ChangeReason: tender.ChangeReason,
+	})
+}
+
+type GetTenderVersionsInput struct {
+	TenderId uuid.UUID `param:"tender_id" validate:"required"`
+	Username string    `query:"username" validate:"required"`
+}
+
+func (r *tenderRoutes) getVersions(c echo.Context) error {
+	var input GetTenderVersionsInput
+	if err := c.Bind(&input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	input.Username = callerUsername(c, input.Username)
+	if err := c.Validate(input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	employeeId, err := r.employeeService.GetEmployeeIdByUsername(c.Request().Context(), input.Username)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusUnauthorized, err)
+	}
+	current, err := r.tenderService.GetTenderById(c.Request().Context(), input.TenderId)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusNotFound, err)
+	}
+	allowed, err := r.policy.CanViewTender(c.Request().Context(), callerPrincipal(c, employeeId), current)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	if !allowed {
+		return errors2.NewErrorResponse(c, http.StatusForbidden, service.ErrPermissionDenied)
+	}
+
+	versions, err := r.tenderService.GetVersions(c.Request().Context(), input.TenderId)
+	if err != nil {
+		if errors.Is(err, service.ErrTenderNotFound) {
+			return errors2.NewErrorResponse(c, http.StatusNotFound, err)
+		}
+		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	return c.JSON(http.StatusOK, versions)
+}
+
+type DiffTenderVersionsInput struct {
+	TenderId uuid.UUID `param:"tender_id" validate:"required"`
+	From     int       `param:"from" validate:"required,gte=1"`
+	To       int       `param:"to" validate:"required,gte=1"`
+	Username string    `query:"username" validate:"required"`
+}
+
+func (r *tenderRoutes) diffVersions(c echo.Context) error {
+	var input DiffTenderVersionsInput
+	if err := c.Bind(&input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	input.Username = callerUsername(c, input.Username)
+	if err := c.Validate(input); err != nil {
+		return errors2.NewErrorResponse(c, http.StatusBadRequest, err)
+	}
+	employeeId, err := r.employeeService.GetEmployeeIdByUsername(c.Request().Context(), input.Username)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusUnauthorized, err)
+	}
+	current, err := r.tenderService.GetTenderById(c.Request().Context(), input.TenderId)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusNotFound, err)
+	}
+	allowed, err := r.policy.CanViewTender(c.Request().Context(), callerPrincipal(c, employeeId), current)
+	if err != nil {
+		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	if !allowed {
+		return errors2.NewErrorResponse(c, http.StatusForbidden, service.ErrPermissionDenied)
+	}
+
+	diff, err := r.tenderService.DiffVersions(c.Request().Context(), service.DiffVersionsInput{
+		Id:   input.TenderId,
+		From: input.From,
+		To:   input.To,
 	})
+	if err != nil {
+		if errors.Is(err, service.ErrVersionNotFound) {
+			return errors2.NewErrorResponse(c, http.StatusNotFound, err)
+		}
+		return errors2.NewErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	return c.JSON(http.StatusOK, diff)
 }
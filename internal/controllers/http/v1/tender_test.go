@@ -0,0 +1,199 @@
+package v1
+
+import (
+	"avito/internal/controllers/validators"
+	"avito/internal/entity"
+	"avito/internal/repo"
+	"avito/internal/repo/repoerrs"
+	"avito/internal/service"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+var errDB = errors.New("db is down")
+
+type fakeEmployeeRepo struct {
+	repo.Employee
+	ids     map[string]uuid.UUID
+	orgs    map[uuid.UUID][]uuid.UUID
+	orgsErr error
+}
+
+func (f *fakeEmployeeRepo) GetEmployeeIdByUsername(_ context.Context, username string) (uuid.UUID, error) {
+	id, ok := f.ids[username]
+	if !ok {
+		return uuid.Nil, repoerrs.ErrNotFound
+	}
+	return id, nil
+}
+
+func (f *fakeEmployeeRepo) GetEmployeeOrgIdsById(_ context.Context, employeeId uuid.UUID) ([]uuid.UUID, error) {
+	if f.orgsErr != nil {
+		return nil, f.orgsErr
+	}
+	return f.orgs[employeeId], nil
+}
+
+// fakeTenderRepo serves tenders from a map and records the tenders it is
+// asked to create.
+type fakeTenderRepo struct {
+	repo.Tender
+	tenders   map[uuid.UUID]*entity.Tender
+	created   []entity.Tender
+	createErr error
+}
+
+func (f *fakeTenderRepo) GetTenderById(_ context.Context, tenderId uuid.UUID) (*entity.Tender, error) {
+	t, ok := f.tenders[tenderId]
+	if !ok {
+		return nil, repoerrs.ErrNotFound
+	}
+	return t, nil
+}
+
+func (f *fakeTenderRepo) CreateTender(_ context.Context, name, description, serviceType string, organisationId uuid.UUID, creatorUsername string, submissionDeadline, decisionDeadline *time.Time) (*entity.Tender, error) {
+	if f.createErr != nil {
+		return nil, f.createErr
+	}
+	t := entity.Tender{
+		Id:                 uuid.New(),
+		Name:               name,
+		Description:        description,
+		Type:               serviceType,
+		Status:             "Created",
+		OrganizationId:     organisationId,
+		Version:            1,
+		CreatorUsername:    creatorUsername,
+		CreatedAt:          time.Now(),
+		SubmissionDeadline: submissionDeadline,
+		DecisionDeadline:   decisionDeadline,
+	}
+	f.created = append(f.created, t)
+	return &t, nil
+}
+
+// newTenderServer serves the tender routes with the real services on top of
+// the fake repos. Requests carry no credentials, so the creator is taken from
+// creatorUsername as in username fallback mode.
+func newTenderServer(tenders *fakeTenderRepo, employees *fakeEmployeeRepo) *echo.Echo {
+	e := echo.New()
+	e.Validator = validators.New()
+	newTenderRoutes(
+		e.Group("/api/tenders"),
+		service.NewTenderService(tenders, employees),
+		service.NewEmployeeService(employees),
+		nil,
+	)
+	return e
+}
+
+func TestCreateTenderChecksOrganizationMembership(t *testing.T) {
+	creatorId, organizationId, otherOrganizationId := uuid.New(), uuid.New(), uuid.New()
+	tests := []struct {
+		name        string
+		employees   *fakeEmployeeRepo
+		tenders     *fakeTenderRepo
+		status      int
+		wantCreated bool
+	}{
+		{
+			name: "responsible of the organization",
+			employees: &fakeEmployeeRepo{
+				ids:  map[string]uuid.UUID{"creator": creatorId},
+				orgs: map[uuid.UUID][]uuid.UUID{creatorId: {otherOrganizationId, organizationId}},
+			},
+			tenders:     &fakeTenderRepo{},
+			status:      http.StatusOK,
+			wantCreated: true,
+		},
+		{
+			name: "responsible of another organization only",
+			employees: &fakeEmployeeRepo{
+				ids:  map[string]uuid.UUID{"creator": creatorId},
+				orgs: map[uuid.UUID][]uuid.UUID{creatorId: {otherOrganizationId}},
+			},
+			tenders: &fakeTenderRepo{},
+			status:  http.StatusForbidden,
+		},
+		{
+			name: "responsible of no organization",
+			employees: &fakeEmployeeRepo{
+				ids: map[string]uuid.UUID{"creator": creatorId},
+			},
+			tenders: &fakeTenderRepo{},
+			status:  http.StatusForbidden,
+		},
+		{
+			name: "membership lookup fails",
+			employees: &fakeEmployeeRepo{
+				ids:     map[string]uuid.UUID{"creator": creatorId},
+				orgsErr: errDB,
+			},
+			tenders: &fakeTenderRepo{},
+			status:  http.StatusInternalServerError,
+		},
+		{
+			name: "tender insert fails",
+			employees: &fakeEmployeeRepo{
+				ids:  map[string]uuid.UUID{"creator": creatorId},
+				orgs: map[uuid.UUID][]uuid.UUID{creatorId: {organizationId}},
+			},
+			tenders: &fakeTenderRepo{createErr: errDB},
+			status:  http.StatusInternalServerError,
+		},
+		{
+			name:      "unknown creator",
+			employees: &fakeEmployeeRepo{},
+			tenders:   &fakeTenderRepo{},
+			status:    http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := `{
+				"name": "Delivery of bricks",
+				"description": "Ten pallets",
+				"serviceType": "Delivery",
+				"organizationId": "` + organizationId.String() + `",
+				"creatorUsername": "creator"
+			}`
+			req := httptest.NewRequest(http.MethodPost, "/api/tenders/new", strings.NewReader(body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			newTenderServer(tt.tenders, tt.employees).ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d, body %s", rec.Code, tt.status, rec.Body)
+			}
+			if !tt.wantCreated {
+				if len(tt.tenders.created) != 0 {
+					t.Fatalf("tender was created: %+v", tt.tenders.created)
+				}
+				return
+			}
+			if len(tt.tenders.created) != 1 {
+				t.Fatalf("created %d tenders, want 1", len(tt.tenders.created))
+			}
+			var got struct {
+				Id             uuid.UUID `json:"id"`
+				OrganizationId uuid.UUID `json:"organization_id"`
+				Status         string    `json:"status"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if got.OrganizationId != organizationId || got.Id != tt.tenders.created[0].Id || got.Status != "Created" {
+				t.Fatalf("unexpected response %s", rec.Body)
+			}
+		})
+	}
+}
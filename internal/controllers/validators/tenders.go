@@ -17,3 +17,10 @@ func EditTenderValidate(name, description *string) error {
 	}
 	return nil
 }
+
+func SearchTendersValidate(q, sort string) error {
+	if sort == "relevance" && q == "" {
+		return fmt.Errorf("invalid params: sort by relevance requires q")
+	}
+	return nil
+}
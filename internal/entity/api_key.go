@@ -0,0 +1,16 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type ApiKey struct {
+	Id             uuid.UUID  `db:"id"`
+	OrganizationId uuid.UUID  `db:"organization_id"`
+	CreatedBy      uuid.UUID  `db:"created_by"`
+	KeyHash        string     `db:"key_hash"`
+	Scopes         []string   `db:"scopes"`
+	CreatedAt      time.Time  `db:"created_at"`
+	RevokedAt      *time.Time `db:"revoked_at"`
+}
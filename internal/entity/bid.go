@@ -6,15 +6,20 @@ import (
 )
 
 type Bid struct {
-	Id          uuid.UUID `db:"id"`
-	Name        string    `db:"name"`
-	Description string    `db:"description"`
-	TenderId    uuid.UUID `db:"tender_id"`
-	Status      string    `db:"status"`
-	Decision    *string   `db:"decision"`
-	AuthorType  string    `db:"author_type"`
-	AuthorId    uuid.UUID `db:"author_id"`
-	Version     int       `db:"version"`
-	CreatedAt   time.Time `db:"created_at"`
-	UpdatedAt   time.Time `db:"updated_at"`
+	Id           uuid.UUID  `db:"id"`
+	Name         string     `db:"name"`
+	Description  string     `db:"description"`
+	TenderId     uuid.UUID  `db:"tender_id"`
+	Status       string     `db:"status"`
+	Decision     *string    `db:"decision"`
+	AuthorType   string     `db:"author_type"`
+	AuthorId     uuid.UUID  `db:"author_id"`
+	Version      int        `db:"version"`
+	CreatedAt    time.Time  `db:"created_at"`
+	UpdatedAt    time.Time  `db:"updated_at"`
+	EditedBy     *uuid.UUID `db:"edited_by"`
+	ChangeReason *string    `db:"change_reason"`
+	// OrganizationId is the organization an "Organization" bid was submitted
+	// for and is nil for "User" bids.
+	OrganizationId *uuid.UUID `db:"organization_id"`
 }
@@ -6,10 +6,11 @@ import (
 )
 
 type Employee struct {
-	Id        uuid.UUID `db:"id"`
-	Username  string    `db:"username"`
-	FirstName *string   `db:"first_name"`
-	LastName  *string   `db:"last_name"`
-	CreatedAt time.Time `db:"created_at"`
-	UpdatedAt time.Time `db:"updated_at"`
+	Id            uuid.UUID  `db:"id"`
+	Username      string     `db:"username"`
+	FirstName     *string    `db:"first_name"`
+	LastName      *string    `db:"last_name"`
+	CreatedAt     time.Time  `db:"created_at"`
+	UpdatedAt     time.Time  `db:"updated_at"`
+	DeactivatedAt *time.Time `db:"deactivated_at"`
 }
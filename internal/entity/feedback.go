@@ -0,0 +1,14 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type BidFeedback struct {
+	Id          uuid.UUID `db:"id"`
+	BidId       uuid.UUID `db:"bid_id"`
+	EmployeeId  uuid.UUID `db:"employee_id"`
+	Description string    `db:"description"`
+	CreatedAt   time.Time `db:"created_at"`
+}
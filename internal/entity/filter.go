@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	SortByName          = "name"
+	SortByCreatedAt     = "createdAt"
+	SortByCreatedAtDesc = "-createdAt"
+	SortByRelevance     = "relevance"
+)
+
+// ListFilter narrows down and orders tender and bid listings. Nil and empty
+// fields are not applied; the time ranges are inclusive.
+type ListFilter struct {
+	OrganizationId *uuid.UUID
+	Statuses       []string
+	CreatedFrom    *time.Time
+	CreatedTo      *time.Time
+	UpdatedFrom    *time.Time
+	UpdatedTo      *time.Time
+	Sort           string
+	After          *Cursor
+}
+
+// Cursor points at the last row of the previous page in the order given by
+// Sort. Only the key of that order is meaningful besides Id.
+type Cursor struct {
+	Sort      string
+	Name      string
+	CreatedAt time.Time
+	Id        uuid.UUID
+}
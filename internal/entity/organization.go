@@ -8,9 +8,20 @@ import (
 type Organization struct {
 	Id          uuid.UUID `db:"id"`
 	Name        string    `db:"name"`
-	Description string    `db:"description"`
-	Type        string    `db:"type"`
-	Status      string    `db:"status"`
+	Description *string   `db:"description"`
+	Type        *string   `db:"type"`
 	CreatedAt   time.Time `db:"created_at"`
 	UpdatedAt   time.Time `db:"updated_at"`
 }
+
+type OrganizationResponsible struct {
+	Id             uuid.UUID `db:"id"`
+	OrganizationId uuid.UUID `db:"organization_id"`
+	UserId         uuid.UUID `db:"user_id"`
+	Role           string    `db:"role"`
+}
+
+const (
+	RoleAdmin  = "Admin"
+	RoleMember = "Member"
+)
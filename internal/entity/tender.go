@@ -6,14 +6,20 @@ import (
 )
 
 type Tender struct {
-	Id              uuid.UUID `db:"id"`
-	Name            string    `db:"name"`
-	Description     string    `db:"description"`
-	Type            string    `db:"type"`
-	Status          string    `db:"status"`
-	OrganizationId  uuid.UUID `db:"organization_id"`
-	Version         int       `db:"version"`
-	CreatorUsername string    `db:"creator_username"`
-	CreatedAt       time.Time `db:"created_at"`
-	UpdatedAt       time.Time `db:"updated_at"`
+	Id              uuid.UUID  `db:"id"`
+	Name            string     `db:"name"`
+	Description     string     `db:"description"`
+	Type            string     `db:"type"`
+	Status          string     `db:"status"`
+	OrganizationId  uuid.UUID  `db:"organization_id"`
+	Version         int        `db:"version"`
+	CreatorUsername string     `db:"creator_username"`
+	CreatedAt       time.Time  `db:"created_at"`
+	UpdatedAt       time.Time  `db:"updated_at"`
+	EditedBy        *uuid.UUID `db:"edited_by"`
+	ChangeReason    *string    `db:"change_reason"`
+	// SubmissionDeadline stops new bids and leaves the tender open for
+	// decisions; the tender is closed automatically at DecisionDeadline only.
+	SubmissionDeadline *time.Time `db:"submission_deadline"`
+	DecisionDeadline   *time.Time `db:"decision_deadline"`
 }
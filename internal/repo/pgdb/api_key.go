@@ -0,0 +1,102 @@
+package pgdb
+
+import (
+	"avito/internal/entity"
+	"avito/internal/repo/repoerrs"
+	"avito/pkg/postgres"
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+type ApiKeyRepo struct {
+	*postgres.Postgres
+}
+
+func NewApiKeyRepo(pg *postgres.Postgres) *ApiKeyRepo {
+	return &ApiKeyRepo{pg}
+}
+
+func (r *ApiKeyRepo) CreateApiKey(ctx context.Context, organizationId, createdBy uuid.UUID, keyHash string, scopes []string) (*entity.ApiKey, error) {
+	request := `INSERT INTO organization_api_key (organization_id, created_by, key_hash, scopes)
+				VALUES 
+				    ($1, $2, $3, $4)
+				RETURNING *`
+	rows, err := r.Pool.Query(ctx, request, organizationId, createdBy, keyHash, scopes)
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("ApiKeyRepo.CreateApiKey - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+	k, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[entity.ApiKey])
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("ApiKeyRepo.CreateApiKey - pgx.CollectOneRow: %v", err)
+	}
+	return &k, nil
+}
+
+func (r *ApiKeyRepo) GetApiKeys(ctx context.Context, organizationId uuid.UUID) ([]entity.ApiKey, error) {
+	request := `SELECT *
+				FROM organization_api_key
+				WHERE organization_id=$1
+				ORDER BY created_at`
+	rows, err := r.Pool.Query(ctx, request, organizationId)
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("ApiKeyRepo.GetApiKeys - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+	keys, err := pgx.CollectRows(rows, pgx.RowToStructByName[entity.ApiKey])
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("ApiKeyRepo.GetApiKeys - pgx.CollectRows: %v", err)
+	}
+	return keys, nil
+}
+
+// GetActiveApiKeyByHash returns the key with the given hash unless it was
+// revoked or its creator is no longer responsible for the key's organization.
+func (r *ApiKeyRepo) GetActiveApiKeyByHash(ctx context.Context, keyHash string) (*entity.ApiKey, error) {
+	request := `SELECT k.*
+				FROM organization_api_key k
+				WHERE k.key_hash=$1 AND k.revoked_at IS NULL
+				  AND EXISTS (
+					SELECT 1
+					FROM organization_responsible r
+					WHERE r.organization_id = k.organization_id AND r.user_id = k.created_by
+				  )`
+	rows, err := r.Pool.Query(ctx, request, keyHash)
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("ApiKeyRepo.GetActiveApiKeyByHash - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+	k, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[entity.ApiKey])
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, repoerrs.ErrNotFound
+	}
+	return &k, nil
+}
+
+func (r *ApiKeyRepo) RevokeApiKey(ctx context.Context, organizationId, keyId uuid.UUID) (*entity.ApiKey, error) {
+	request := `UPDATE organization_api_key
+				SET revoked_at=CURRENT_TIMESTAMP
+				WHERE id=$1 AND organization_id=$2 AND revoked_at IS NULL
+				RETURNING *`
+	rows, err := r.Pool.Query(ctx, request, keyId, organizationId)
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("ApiKeyRepo.RevokeApiKey - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+	k, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[entity.ApiKey])
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, repoerrs.ErrNotFound
+	}
+	return &k, nil
+}
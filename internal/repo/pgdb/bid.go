@@ -6,8 +6,10 @@ import (
 	"avito/pkg/postgres"
 	"context"
 	"fmt"
+	"github.com/Masterminds/squirrel"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -19,12 +21,44 @@ func NewBidRepo(pg *postgres.Postgres) *BidRepo {
 	return &BidRepo{pg}
 }
 
-func (r *BidRepo) CreateBid(ctx context.Context, name, description string, tenderId uuid.UUID, authorType string, authorId uuid.UUID) (*entity.Bid, error) {
-	request := `INSERT INTO bid (name, description, tender_id, author_type, author_id)
-				VALUES 
-				    ($1, $2, $3, $4, $5)
+// bidColumns lists every column of bid and bid_current.
+const bidColumns = `id, name, description, tender_id, status, decision, author_type, author_id, version,
+				    created_at, updated_at, edited_by, change_reason, organization_id`
+
+// bidCurrentInsert copies the versions returned by the inserted CTE into
+// bid_current. See tenderCurrentInsert.
+const bidCurrentInsert = `INSERT INTO bid_current (` + bidColumns + `)
+				SELECT ` + bidColumns + ` FROM inserted`
+
+// bidCurrentUpsert makes the inserted version the current one.
+const bidCurrentUpsert = `ON CONFLICT (id) DO UPDATE SET
+				    name = EXCLUDED.name,
+				    description = EXCLUDED.description,
+				    tender_id = EXCLUDED.tender_id,
+				    status = EXCLUDED.status,
+				    decision = EXCLUDED.decision,
+				    author_type = EXCLUDED.author_type,
+				    author_id = EXCLUDED.author_id,
+				    version = EXCLUDED.version,
+				    created_at = EXCLUDED.created_at,
+				    updated_at = EXCLUDED.updated_at,
+				    edited_by = EXCLUDED.edited_by,
+				    change_reason = EXCLUDED.change_reason,
+				    organization_id = EXCLUDED.organization_id`
+
+func (r *BidRepo) CreateBid(ctx context.Context, name, description string, tenderId uuid.UUID, authorType string, authorId uuid.UUID, organizationId *uuid.UUID) (*entity.Bid, error) {
+	request := `WITH inserted AS (
+					INSERT INTO bid (name, description, tender_id, author_type, author_id, organization_id)
+					VALUES ($1, $2, $3, $4, $5, $6)
+					RETURNING *
+				)
+				` + bidCurrentInsert + `
 				RETURNING *`
-	rows, err := r.Pool.Query(ctx, request, name, description, tenderId, authorType, authorId)
+	rows, err := r.Pool.Query(ctx, request, name, description, tenderId, authorType, authorId, organizationId)
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("BidRepo.CreateBid - r.Pool.Query: %v", err)
+	}
 	defer rows.Close()
 	b, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[entity.Bid])
 	if err != nil {
@@ -34,21 +68,28 @@ func (r *BidRepo) CreateBid(ctx context.Context, name, description string, tende
 	return &b, nil
 }
 
-func (r *BidRepo) GetMyBids(ctx context.Context, authorId uuid.UUID, limit, offset int) ([]entity.Bid, error) {
-	request := `SELECT *
-				FROM bid
-				WHERE author_id=$1 AND version = (SELECT MAX(version)
-                	FROM bid AS b
-                	WHERE b.id = bid.id)
-				ORDER BY name
-				LIMIT $2
-				OFFSET $3`
-	rows, err := r.Pool.Query(ctx, request, authorId, limit, offset)
-	defer rows.Close()
+// myBidsQuery selects the latest versions of the bids authored by the employee
+// that match the filter.
+func (r *BidRepo) myBidsQuery(columns string, authorId uuid.UUID, filter entity.ListFilter) squirrel.SelectBuilder {
+	q := r.Builder.Select(columns).
+		From("bid_current").
+		Where(squirrel.Eq{"author_id": authorId})
+	return applyFilter(q, filter)
+}
+
+func (r *BidRepo) GetMyBids(ctx context.Context, authorId uuid.UUID, filter entity.ListFilter, limit, offset int) ([]entity.Bid, error) {
+	q := applySort(r.myBidsQuery("*", authorId, filter), filter.Sort)
+	request, args, err := q.Limit(uint64(limit)).Offset(uint64(offset)).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("BidRepo.GetMyBids - q.ToSql: %v", err)
+	}
+
+	rows, err := r.Pool.Query(ctx, request, args...)
 	if err != nil {
 		log.Debugf("err: %v", err)
 		return nil, fmt.Errorf("BidRepo.GetMyBids - r.Pool.Query: %v", err)
 	}
+	defer rows.Close()
 	bids, err := pgx.CollectRows(rows, pgx.RowToStructByName[entity.Bid])
 	if err != nil {
 		log.Debugf("err: %v", err)
@@ -57,12 +98,47 @@ func (r *BidRepo) GetMyBids(ctx context.Context, authorId uuid.UUID, limit, offs
 	return bids, nil
 }
 
+// CountMyBids counts every bid GetMyBids can list for the filter, ignoring its
+// cursor.
+func (r *BidRepo) CountMyBids(ctx context.Context, authorId uuid.UUID, filter entity.ListFilter) (int, error) {
+	filter.After = nil
+	count, err := countRows(ctx, r.Pool, r.myBidsQuery("COUNT(*)", authorId, filter))
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return 0, fmt.Errorf("BidRepo.CountMyBids - countRows: %v", err)
+	}
+	return count, nil
+}
+
+func (r *BidRepo) GetBidsForTender(ctx context.Context, tenderId, employeeId uuid.UUID, withPublished bool, filter entity.ListFilter, limit, offset int) ([]entity.Bid, error) {
+	q := r.Builder.Select("*").
+		From("bid_current").
+		Where(squirrel.Eq{"tender_id": tenderId}).
+		Where("(author_id = ? OR (? AND status = 'Published'))", employeeId, withPublished)
+	q = applySort(applyFilter(q, filter), filter.Sort)
+	request, args, err := q.Limit(uint64(limit)).Offset(uint64(offset)).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("BidRepo.GetBidsForTender - q.ToSql: %v", err)
+	}
+
+	rows, err := r.Pool.Query(ctx, request, args...)
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("BidRepo.GetBidsForTender - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+	bids, err := pgx.CollectRows(rows, pgx.RowToStructByName[entity.Bid])
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("BidRepo.GetBidsForTender - pgx.CollectRows: %v", err)
+	}
+	return bids, nil
+}
+
 func (r *BidRepo) GetBidById(ctx context.Context, bidId uuid.UUID) (*entity.Bid, error) {
 	request := `SELECT *
-				FROM bid
-				WHERE id=$1 AND version = (SELECT MAX(version)
-                	FROM bid AS b
-                	WHERE b.id = bid.id)`
+				FROM bid_current
+				WHERE id=$1`
 	rows, err := r.Pool.Query(ctx, request, bidId)
 	if err != nil {
 		log.Debugf("err: %v", err)
@@ -76,108 +152,392 @@ func (r *BidRepo) GetBidById(ctx context.Context, bidId uuid.UUID) (*entity.Bid,
 	return &b, nil
 }
 
-func (r *BidRepo) PutStatus(ctx context.Context, BidId uuid.UUID, status string) (*entity.Bid, error) {
-	request := `UPDATE bid 
-				SET status=$1 
-				WHERE id=$2 AND version = (SELECT MAX(version)
-                	FROM bid AS b
-                	WHERE b.id = bid.id)
-                RETURNING *`
-	rows, err := r.Pool.Query(ctx, request, status, BidId)
-	defer rows.Close()
+// PutStatus records the status change as a new version of the bid. The locked
+// bid must still be in fromStatus, otherwise repoerrs.ErrStatusMismatch is
+// returned.
+func (r *BidRepo) PutStatus(ctx context.Context, bidId uuid.UUID, fromStatus, status string, editedBy uuid.UUID) (*entity.Bid, error) {
+	tx, err := r.Pool.Begin(ctx)
 	if err != nil {
 		log.Debugf("err: %v", err)
-		return nil, fmt.Errorf("BidRepo.PutStatus - r.Pool.Query: %v", err)
+		return nil, fmt.Errorf("BidRepo.PutStatus - r.Pool.Begin: %v", err)
 	}
+	defer func() { _ = tx.Rollback(ctx) }()
 
-	b, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[entity.Bid])
-
+	b, err := r.lockLatest(ctx, tx, bidId)
+	if err != nil {
+		return nil, err
+	}
+	if b.Status != fromStatus {
+		return nil, repoerrs.ErrStatusMismatch
+	}
+	b.Status = status
+	b.Version++
+	b.EditedBy = &editedBy
+	b.ChangeReason = nil
+	result, err := r.insertVersion(ctx, tx, b)
 	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
 		log.Debugf("err: %v", err)
-		return nil, repoerrs.ErrNotFound
+		return nil, fmt.Errorf("BidRepo.PutStatus - tx.Commit: %v", err)
 	}
-	return &b, nil
+	return result, nil
 }
 
-func (r *BidRepo) EditBid(ctx context.Context, bidId uuid.UUID, name, description string) (*entity.Bid, error) {
-	prevVReq := `SELECT *
-				 FROM bid
-			     WHERE id=$1 AND version = (SELECT MAX(version)
-				 FROM bid AS b
-				 WHERE b.id = bid.id)
-				 `
-	rows, err := r.Pool.Query(ctx, prevVReq, bidId)
-
-	defer rows.Close()
+// lockLatest returns the latest version of the bid and locks its current row
+// until the end of the transaction. See TenderRepo.lockLatest for how
+// concurrent edits are handled.
+func (r *BidRepo) lockLatest(ctx context.Context, tx pgx.Tx, bidId uuid.UUID) (entity.Bid, error) {
+	request := `SELECT *
+				FROM bid_current
+				WHERE id=$1
+				FOR UPDATE`
+	rows, err := tx.Query(ctx, request, bidId)
 	if err != nil {
 		log.Debugf("err: %v", err)
-		return nil, fmt.Errorf("BidRepo.EditBid - GetPrevVersion - r.Pool.Query: %v", err)
+		return entity.Bid{}, fmt.Errorf("BidRepo.lockLatest - tx.Query: %v", err)
 	}
 	b, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[entity.Bid])
 	if err != nil {
 		log.Debugf("err: %v", err)
-		return nil, repoerrs.ErrNotFound
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.Bid{}, repoerrs.ErrNotFound
+		}
+		return entity.Bid{}, fmt.Errorf("BidRepo.lockLatest - pgx.CollectOneRow: %v", err)
 	}
+	return b, nil
+}
 
-	request := `INSERT INTO bid (id, name, description, tender_id, status, author_type, author_id, version)
-				VALUES 
-				    ($1, $2, $3, $4, $5, $6, $7, (
-				    	(SELECT COALESCE(MAX(version), 0) + 1
-				    	 FROM bid AS b
-				     	 WHERE b.id = $1)))
+// insertVersion appends the version to the history and makes it current.
+func (r *BidRepo) insertVersion(ctx context.Context, tx pgx.Tx, b entity.Bid) (*entity.Bid, error) {
+	request := `WITH inserted AS (
+					INSERT INTO bid (id, name, description, tender_id, status, decision, author_type, author_id, version, created_at, edited_by, change_reason, organization_id)
+					VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+					RETURNING *
+				)
+				` + bidCurrentInsert + `
+				` + bidCurrentUpsert + `
 				RETURNING *`
-	if name == "" {
-		name = b.Name
+	rows, err := tx.Query(ctx, request, b.Id, b.Name, b.Description, b.TenderId, b.Status, b.Decision, b.AuthorType, b.AuthorId, b.Version, b.CreatedAt, b.EditedBy, b.ChangeReason, b.OrganizationId)
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("BidRepo.insertVersion - tx.Query: %v", err)
 	}
-	if description == "" {
-		description = b.Description
+	b, err = pgx.CollectOneRow(rows, pgx.RowToStructByName[entity.Bid])
+	if err != nil {
+		log.Debugf("err: %v", err)
+		if isUniqueViolation(err) {
+			return nil, repoerrs.ErrConflict
+		}
+		return nil, fmt.Errorf("BidRepo.insertVersion - pgx.CollectOneRow: %v", err)
 	}
+	return &b, nil
+}
 
-	result, err := r.Pool.Query(ctx, request, b.Id, name, description, b.TenderId, b.Status, b.AuthorType, b.AuthorId)
-	defer result.Close()
-	b, err = pgx.CollectOneRow(result, pgx.RowToStructByName[entity.Bid])
+// EditBid creates a new version of the bid. A non-zero expectedVersion must
+// match the latest version, otherwise repoerrs.ErrVersionMismatch is returned.
+func (r *BidRepo) EditBid(ctx context.Context, bidId uuid.UUID, expectedVersion int, name, description string, editedBy uuid.UUID, reason string) (*entity.Bid, error) {
+	tx, err := r.Pool.Begin(ctx)
 	if err != nil {
 		log.Debugf("err: %v", err)
-		return nil, fmt.Errorf("BidRepo.EditBid - r.Pool.Query: %v", err)
+		return nil, fmt.Errorf("BidRepo.EditBid - r.Pool.Begin: %v", err)
 	}
-	return &b, nil
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	b, err := r.lockLatest(ctx, tx, bidId)
+	if err != nil {
+		return nil, err
+	}
+	if expectedVersion != 0 && b.Version != expectedVersion {
+		return nil, repoerrs.ErrVersionMismatch
+	}
+	if name != "" {
+		b.Name = name
+	}
+	if description != "" {
+		b.Description = description
+	}
+	b.Version++
+	b.EditedBy = &editedBy
+	b.ChangeReason = nullableString(reason)
+	result, err := r.insertVersion(ctx, tx, b)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("BidRepo.EditBid - tx.Commit: %v", err)
+	}
+	return result, nil
 }
 
-func (r *BidRepo) RollbackVersion(ctx context.Context, bidId uuid.UUID, version int) (*entity.Bid, error) {
+// RollbackVersion restores the name and description of the given version as a
+// new version. Status and decision are kept from the latest version.
+func (r *BidRepo) RollbackVersion(ctx context.Context, bidId uuid.UUID, version int, editedBy uuid.UUID, reason string) (*entity.Bid, error) {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("BidRepo.RollbackVersion - r.Pool.Begin: %v", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	latest, err := r.lockLatest(ctx, tx, bidId)
+	if err != nil {
+		return nil, err
+	}
 	prevVReq := `SELECT *
 				 FROM bid
 			     WHERE id=$1 AND version = $2
 				 `
-	rows, err := r.Pool.Query(ctx, prevVReq, bidId, version)
-	defer rows.Close()
+	rows, err := tx.Query(ctx, prevVReq, bidId, version)
 	if err != nil {
 		log.Debugf("err: %v", err)
-		return nil, fmt.Errorf("BidRepo.EditTender - GetPrevVersion - r.Pool.Query: %v", err)
+		return nil, fmt.Errorf("BidRepo.RollbackVersion - GetPrevVersion - tx.Query: %v", err)
 	}
 	b, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[entity.Bid])
 	if err != nil {
 		log.Debugf("err: %v", err)
 		return nil, repoerrs.ErrVersionNotFound
 	}
-	lastVReq := `SELECT MAX(version)
+	b.Status = latest.Status
+	b.Decision = latest.Decision
+	b.Version = latest.Version + 1
+	b.EditedBy = &editedBy
+	b.ChangeReason = nullableString(reason)
+	result, err := r.insertVersion(ctx, tx, b)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("BidRepo.RollbackVersion - tx.Commit: %v", err)
+	}
+	return result, nil
+}
+
+func (r *BidRepo) GetVersions(ctx context.Context, bidId uuid.UUID) ([]entity.Bid, error) {
+	request := `SELECT *
 				FROM bid
-				WHERE id=$1`
-	var lastV int
-	if err := r.Pool.QueryRow(ctx, lastVReq, bidId).Scan(&lastV); err != nil {
+				WHERE id=$1
+				ORDER BY version`
+	rows, err := r.Pool.Query(ctx, request, bidId)
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("BidRepo.GetVersions - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+	bids, err := pgx.CollectRows(rows, pgx.RowToStructByName[entity.Bid])
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("BidRepo.GetVersions - pgx.CollectRows: %v", err)
+	}
+	if len(bids) == 0 {
+		return nil, repoerrs.ErrNotFound
+	}
+	return bids, nil
+}
+
+func (r *BidRepo) GetVersion(ctx context.Context, bidId uuid.UUID, version int) (*entity.Bid, error) {
+	request := `SELECT *
+				FROM bid
+				WHERE id=$1 AND version=$2`
+	rows, err := r.Pool.Query(ctx, request, bidId, version)
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("BidRepo.GetVersion - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+	b, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[entity.Bid])
+	if err != nil {
 		log.Debugf("err: %v", err)
 		return nil, repoerrs.ErrVersionNotFound
 	}
-	request := `INSERT INTO bid (id, name, description, tender_id, status, author_type, author_id, version)
+	return &b, nil
+}
+
+// SubmitDecision stores the employee's decision and applies its outcome in one
+// transaction: a rejection rejects the bid, and once approvals of the current
+// responsibles of the tender's organization reach quorum the bid is approved,
+// the tender is closed with closeReason and the competing bids are marked lost.
+// Every outcome is written as a new version.
+func (r *BidRepo) SubmitDecision(ctx context.Context, tenderId, bidId, employeeId uuid.UUID, decision string, quorum int, closeReason string) (*entity.Bid, error) {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("BidRepo.SubmitDecision - r.Pool.Begin: %v", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	tenderReq := `SELECT status, organization_id
+				  FROM tender_current
+				  WHERE id=$1
+				  FOR UPDATE`
+	var tenderStatus string
+	var organizationId uuid.UUID
+	if err := tx.QueryRow(ctx, tenderReq, tenderId).Scan(&tenderStatus, &organizationId); err != nil {
+		log.Debugf("err: %v", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repoerrs.ErrNotFound
+		}
+		return nil, fmt.Errorf("BidRepo.SubmitDecision - LockTender - tx.QueryRow: %v", err)
+	}
+	if tenderStatus == "Closed" {
+		return nil, repoerrs.ErrClosed
+	}
+
+	b, err := r.lockLatest(ctx, tx, bidId)
+	if err != nil {
+		return nil, err
+	}
+	if b.Decision != nil {
+		return nil, repoerrs.ErrAlreadyDecided
+	}
+
+	decisionReq := `INSERT INTO bid_decision (bid_id, employee_id, decision)
+					VALUES
+					    ($1, $2, $3)
+					ON CONFLICT (bid_id, employee_id) DO NOTHING`
+	tag, err := tx.Exec(ctx, decisionReq, bidId, employeeId, decision)
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("BidRepo.SubmitDecision - InsertDecision - tx.Exec: %v", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, repoerrs.ErrAlreadyExists
+	}
+
+	result := &b
+	switch decision {
+	case "Rejected":
+		b.Decision = &decision
+		b.Version++
+		b.EditedBy = &employeeId
+		b.ChangeReason = nil
+		if result, err = r.insertVersion(ctx, tx, b); err != nil {
+			return nil, err
+		}
+	case "Approved":
+		// approvals of employees who are no longer responsible for the
+		// organization, or were deactivated, do not count
+		approvalsReq := `SELECT COUNT(*)
+						 FROM bid_decision AS d
+								  JOIN organization_responsible AS r
+									   ON r.user_id = d.employee_id AND r.organization_id = $2
+								  JOIN employee AS e
+									   ON e.id = d.employee_id AND e.deactivated_at IS NULL
+						 WHERE d.bid_id=$1 AND d.decision='Approved'`
+		var approvals int
+		if err := tx.QueryRow(ctx, approvalsReq, bidId, organizationId).Scan(&approvals); err != nil {
+			log.Debugf("err: %v", err)
+			return nil, fmt.Errorf("BidRepo.SubmitDecision - CountApprovals - tx.QueryRow: %v", err)
+		}
+		if approvals >= quorum {
+			b.Decision = &decision
+			b.Version++
+			b.EditedBy = &employeeId
+			b.ChangeReason = &closeReason
+			if result, err = r.insertVersion(ctx, tx, b); err != nil {
+				return nil, err
+			}
+			closeTenderReq := `WITH inserted AS (
+								   INSERT INTO tender (id, name, description, type, organization_id, creator_username, status, version, created_at, edited_by, change_reason, submission_deadline, decision_deadline)
+								   SELECT id, name, description, type, organization_id, creator_username, 'Closed'::tender_status, version + 1, created_at, $2, $3, submission_deadline, decision_deadline
+								   FROM tender_current
+								   WHERE id=$1
+								   RETURNING *
+							   )
+							   ` + tenderCurrentInsert + `
+							   ` + tenderCurrentUpsert
+			if _, err := tx.Exec(ctx, closeTenderReq, tenderId, employeeId, closeReason); err != nil {
+				log.Debugf("err: %v", err)
+				return nil, fmt.Errorf("BidRepo.SubmitDecision - CloseTender - tx.Exec: %v", err)
+			}
+			loseReq := `WITH competing AS (
+							SELECT *
+							FROM bid_current
+							WHERE tender_id=$1 AND id<>$2 AND decision IS NULL
+							FOR UPDATE
+						),
+						inserted AS (
+							INSERT INTO bid (id, name, description, tender_id, status, decision, author_type, author_id, version, created_at, edited_by, change_reason, organization_id)
+							SELECT id, name, description, tender_id, status, 'Lost', author_type, author_id, version + 1, created_at, $3::uuid, $4, organization_id
+							FROM competing
+							RETURNING *
+						)
+						` + bidCurrentInsert + `
+						` + bidCurrentUpsert
+			if _, err := tx.Exec(ctx, loseReq, tenderId, bidId, employeeId, closeReason); err != nil {
+				log.Debugf("err: %v", err)
+				if isUniqueViolation(err) {
+					return nil, repoerrs.ErrConflict
+				}
+				return nil, fmt.Errorf("BidRepo.SubmitDecision - MarkLost - tx.Exec: %v", err)
+			}
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("BidRepo.SubmitDecision - tx.Commit: %v", err)
+	}
+	return result, nil
+}
+
+func (r *BidRepo) CreateFeedback(ctx context.Context, bidId, employeeId uuid.UUID, description string) (*entity.BidFeedback, error) {
+	request := `INSERT INTO bid_feedback (bid_id, employee_id, description)
 				VALUES 
-				    ($1, $2, $3, $4, $5, $6, $7, $8)
+				    ($1, $2, $3)
 				RETURNING *`
+	rows, err := r.Pool.Query(ctx, request, bidId, employeeId, description)
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("BidRepo.CreateFeedback - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+	f, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[entity.BidFeedback])
+	if err != nil {
+		log.Debugf("err: %v", err)
+		if isForeignKeyViolation(err) {
+			return nil, repoerrs.ErrNotFound
+		}
+		return nil, fmt.Errorf("BidRepo.CreateFeedback - pgx.CollectOneRow: %v", err)
+	}
+	return &f, nil
+}
 
-	result, err := r.Pool.Query(ctx, request, b.Id, b.Name, b.Description, b.TenderId, b.Status, b.AuthorType, b.AuthorId, lastV+1)
-	defer result.Close()
-	b, err = pgx.CollectOneRow(result, pgx.RowToStructByName[entity.Bid])
+func (r *BidRepo) GetAuthorFeedback(ctx context.Context, authorId uuid.UUID, limit, offset int) ([]entity.BidFeedback, error) {
+	request := `SELECT *
+				FROM bid_feedback
+				WHERE bid_id IN (SELECT id
+					FROM bid_current
+					WHERE author_id=$1)
+				ORDER BY created_at DESC
+				LIMIT $2
+				OFFSET $3`
+	rows, err := r.Pool.Query(ctx, request, authorId, limit, offset)
 	if err != nil {
 		log.Debugf("err: %v", err)
-		return nil, fmt.Errorf("BidRepo.RollbackVersion - r.Pool.Query: %v", err)
+		return nil, fmt.Errorf("BidRepo.GetAuthorFeedback - r.Pool.Query: %v", err)
 	}
-	return &b, nil
+	defer rows.Close()
+	feedback, err := pgx.CollectRows(rows, pgx.RowToStructByName[entity.BidFeedback])
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("BidRepo.GetAuthorFeedback - pgx.CollectRows: %v", err)
+	}
+	return feedback, nil
+}
+
+// HasAuthorBid reports whether the employee has authored a bid on the tender.
+func (r *BidRepo) HasAuthorBid(ctx context.Context, tenderId, authorId uuid.UUID) (bool, error) {
+	request := `SELECT EXISTS (SELECT 1
+							   FROM bid_current
+							   WHERE tender_id=$1 AND author_id=$2)`
+	var exists bool
+	if err := r.Pool.QueryRow(ctx, request, tenderId, authorId).Scan(&exists); err != nil {
+		log.Debugf("err: %v", err)
+		return false, fmt.Errorf("BidRepo.HasAuthorBid - r.Pool.QueryRow: %v", err)
+	}
+	return exists, nil
 }
@@ -0,0 +1,161 @@
+package pgdb
+
+import (
+	"avito/internal/entity"
+	"avito/pkg/postgres"
+	"context"
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"os"
+	"testing"
+)
+
+// The listing benchmarks need a migrated database, for example the one the
+// service runs against locally:
+//
+//	BENCH_POSTGRES_CONN=postgres://... go test ./internal/repo/pgdb -run '^$' -bench TenderListing -benchtime 200x
+//
+// They seed benchTenders tenders with benchVersions versions each into a
+// throwaway organization and delete it afterwards.
+const (
+	benchTenders  = 100_000
+	benchVersions = 10
+	benchPageSize = 50
+)
+
+var benchServiceTypes = []string{"Construction", "Delivery", "Manufacture"}
+
+func benchPostgres(b *testing.B) *postgres.Postgres {
+	b.Helper()
+	url := os.Getenv("BENCH_POSTGRES_CONN")
+	if url == "" {
+		b.Skip("BENCH_POSTGRES_CONN is not set")
+	}
+	pg, err := postgres.New(url, postgres.MaxPoolSize(4))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(pg.Close)
+	return pg
+}
+
+// seedTenderVersions writes tenders*versions history rows, the latest version
+// of every tender published, and fills tender_current the way the
+// current_versions migration backfills it.
+func seedTenderVersions(b *testing.B, pg *postgres.Postgres, tenders, versions int) (uuid.UUID, string) {
+	b.Helper()
+	ctx := context.Background()
+	username := "bench-" + uuid.NewString()[:8]
+
+	var organizationId uuid.UUID
+	err := pg.Pool.QueryRow(ctx, `INSERT INTO organization (name) VALUES ($1) RETURNING id`, username).Scan(&organizationId)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() {
+		// tender and tender_current rows go with the organization
+		if _, err := pg.Pool.Exec(context.Background(), `DELETE FROM organization WHERE id=$1`, organizationId); err != nil {
+			b.Error(err)
+		}
+	})
+
+	history := `INSERT INTO tender (id, name, description, type, status, organization_id, version, creator_username)
+				SELECT t.id,
+					   'tender ' || t.n,
+					   'benchmark tender, version ' || v.version,
+					   (ARRAY ['Construction', 'Delivery', 'Manufacture']::service_type[])[1 + t.n % 3],
+					   CASE WHEN v.version = $3 THEN 'Published' ELSE 'Created' END::tender_status,
+					   $1,
+					   v.version,
+					   $2
+				FROM (SELECT uuid_generate_v4() AS id, n FROM generate_series(1, $4) AS n) AS t
+						 CROSS JOIN generate_series(1, $3) AS v(version)`
+	if _, err := pg.Pool.Exec(ctx, history, organizationId, username, versions, tenders); err != nil {
+		b.Fatal(err)
+	}
+	current := `INSERT INTO tender_current (` + tenderColumns + `)
+				SELECT DISTINCT ON (id) ` + tenderColumns + `
+				FROM tender
+				WHERE organization_id = $1
+				ORDER BY id, version DESC`
+	if _, err := pg.Pool.Exec(ctx, current, organizationId); err != nil {
+		b.Fatal(err)
+	}
+	if _, err := pg.Pool.Exec(ctx, `ANALYZE tender, tender_current`); err != nil {
+		b.Fatal(err)
+	}
+	return organizationId, username
+}
+
+// BenchmarkTenderListing measures the first page of the public and the
+// personal tender listing at benchTenders*benchVersions versions, against the
+// correlated MAX(version) subquery the listings used before tender_current.
+func BenchmarkTenderListing(b *testing.B) {
+	pg := benchPostgres(b)
+	r := NewTenderRepo(pg)
+	ctx := context.Background()
+	_, username := seedTenderVersions(b, pg, benchTenders, benchVersions)
+	filter := entity.ListFilter{Sort: entity.SortByName}
+
+	b.Run("GetTenders", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			tenders, err := r.GetTenders(ctx, benchServiceTypes, "", filter, benchPageSize, 0)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if len(tenders) != benchPageSize {
+				b.Fatalf("got %d tenders, want %d", len(tenders), benchPageSize)
+			}
+		}
+	})
+	b.Run("GetMyTenders", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			tenders, err := r.GetMyTenders(ctx, username, filter, benchPageSize, 0)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if len(tenders) != benchPageSize {
+				b.Fatalf("got %d tenders, want %d", len(tenders), benchPageSize)
+			}
+		}
+	})
+	b.Run("GetTenders/MaxVersionSubquery", func(b *testing.B) {
+		benchLegacyListing(b, pg, r.Builder.Select("*").
+			From("tender").
+			Where(squirrel.Eq{"type": benchServiceTypes, "status": "Published"}))
+	})
+	b.Run("GetMyTenders/MaxVersionSubquery", func(b *testing.B) {
+		benchLegacyListing(b, pg, r.Builder.Select("*").
+			From("tender").
+			Where(squirrel.Eq{"creator_username": username}))
+	})
+}
+
+// benchLegacyListing runs a listing over the history table that keeps the
+// latest versions with a correlated subquery per row.
+func benchLegacyListing(b *testing.B, pg *postgres.Postgres, q squirrel.SelectBuilder) {
+	request, args, err := q.
+		Where("version = (SELECT MAX(version) FROM tender AS latest WHERE latest.id = tender.id)").
+		OrderBy("name", "id").
+		Limit(benchPageSize).
+		ToSql()
+	if err != nil {
+		b.Fatal(err)
+	}
+	ctx := context.Background()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rows, err := pg.Pool.Query(ctx, request, args...)
+		if err != nil {
+			b.Fatal(err)
+		}
+		tenders, err := pgx.CollectRows(rows, pgx.RowToStructByName[entity.Tender])
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(tenders) == 0 {
+			b.Fatal("no tenders listed")
+		}
+	}
+}
@@ -21,9 +21,11 @@ func NewEmployeeRepo(pg *postgres.Postgres) *EmployeeRepo {
 	return &EmployeeRepo{pg}
 }
 
+// GetEmployeeIdByUsername resolves an active employee; deactivated employees
+// are reported as not found so they can no longer act.
 func (r *EmployeeRepo) GetEmployeeIdByUsername(ctx context.Context, username string) (uuid.UUID, error) {
 	var id uuid.UUID
-	err := r.Pool.QueryRow(ctx, "SELECT id FROM employee WHERE username=$1", username).Scan(&id)
+	err := r.Pool.QueryRow(ctx, "SELECT id FROM employee WHERE username=$1 AND deactivated_at IS NULL", username).Scan(&id)
 	if err != nil {
 		log.Debugf("err: %v", err)
 		if errors.Is(err, sql.ErrNoRows) {
@@ -34,10 +36,11 @@ func (r *EmployeeRepo) GetEmployeeIdByUsername(ctx context.Context, username str
 	return id, nil
 }
 
+// GetEmployeeById returns an active employee, like GetEmployeeIdByUsername.
 func (r *EmployeeRepo) GetEmployeeById(ctx context.Context, id uuid.UUID) (*entity.Employee, error) {
 	request := `SELECT * 
 				FROM employee
-				WHERE id = $1`
+				WHERE id = $1 AND deactivated_at IS NULL`
 	rows, err := r.Pool.Query(ctx, request, id)
 	defer rows.Close()
 	if err != nil {
@@ -52,18 +55,132 @@ func (r *EmployeeRepo) GetEmployeeById(ctx context.Context, id uuid.UUID) (*enti
 	return &e, nil
 }
 
-func (r *EmployeeRepo) GetEmployeeOrgIdById(ctx context.Context, employeeId uuid.UUID) (uuid.UUID, error) {
+// GetEmployeeByUsername returns the employee whether or not they are active.
+func (r *EmployeeRepo) GetEmployeeByUsername(ctx context.Context, username string) (*entity.Employee, error) {
+	request := `SELECT *
+				FROM employee
+				WHERE username=$1`
+	rows, err := r.Pool.Query(ctx, request, username)
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("EmployeeRepo.GetEmployeeByUsername - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+	e, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[entity.Employee])
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, repoerrs.ErrNotFound
+	}
+	return &e, nil
+}
+
+func (r *EmployeeRepo) CreateEmployee(ctx context.Context, username string, firstName, lastName *string) (*entity.Employee, error) {
+	request := `INSERT INTO employee (username, first_name, last_name)
+				VALUES 
+				    ($1, $2, $3)
+				RETURNING *`
+	rows, err := r.Pool.Query(ctx, request, username, firstName, lastName)
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("EmployeeRepo.CreateEmployee - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+	e, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[entity.Employee])
+	if err != nil {
+		log.Debugf("err: %v", err)
+		if isUniqueViolation(err) {
+			return nil, repoerrs.ErrAlreadyExists
+		}
+		return nil, fmt.Errorf("EmployeeRepo.CreateEmployee - pgx.CollectOneRow: %v", err)
+	}
+	return &e, nil
+}
+
+// UpdateEmployeeNames overwrites only the names that are not nil.
+func (r *EmployeeRepo) UpdateEmployeeNames(ctx context.Context, id uuid.UUID, firstName, lastName *string) (*entity.Employee, error) {
+	request := `UPDATE employee
+				SET first_name=COALESCE($2, first_name),
+				    last_name=COALESCE($3, last_name),
+				    updated_at=CURRENT_TIMESTAMP
+				WHERE id=$1 AND deactivated_at IS NULL
+				RETURNING *`
+	rows, err := r.Pool.Query(ctx, request, id, firstName, lastName)
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("EmployeeRepo.UpdateEmployeeNames - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+	e, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[entity.Employee])
+	if err != nil {
+		log.Debugf("err: %v", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repoerrs.ErrNotFound
+		}
+		return nil, fmt.Errorf("EmployeeRepo.UpdateEmployeeNames - pgx.CollectOneRow: %v", err)
+	}
+	return &e, nil
+}
+
+// DeactivateEmployee keeps the row, so tenders, bids and decisions still
+// reference it, but the employee can no longer authenticate or act.
+func (r *EmployeeRepo) DeactivateEmployee(ctx context.Context, id uuid.UUID) (*entity.Employee, error) {
+	request := `UPDATE employee
+				SET deactivated_at=CURRENT_TIMESTAMP,
+				    updated_at=CURRENT_TIMESTAMP
+				WHERE id=$1 AND deactivated_at IS NULL
+				RETURNING *`
+	rows, err := r.Pool.Query(ctx, request, id)
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("EmployeeRepo.DeactivateEmployee - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+	e, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[entity.Employee])
+	if err != nil {
+		log.Debugf("err: %v", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repoerrs.ErrNotFound
+		}
+		return nil, fmt.Errorf("EmployeeRepo.DeactivateEmployee - pgx.CollectOneRow: %v", err)
+	}
+	return &e, nil
+}
+
+// GetEmployeeOrgIdsById returns every organization the employee is responsible
+// for, which is empty for employees outside any organization.
+func (r *EmployeeRepo) GetEmployeeOrgIdsById(ctx context.Context, employeeId uuid.UUID) ([]uuid.UUID, error) {
 	request := `SELECT organization_id
 				FROM organization_responsible
-				WHERE user_id = $1`
-	var id uuid.UUID
-	err := r.Pool.QueryRow(ctx, request, employeeId).Scan(&id)
+				WHERE user_id = $1
+				ORDER BY organization_id`
+	rows, err := r.Pool.Query(ctx, request, employeeId)
 	if err != nil {
 		log.Debugf("err: %v", err)
-		if errors.Is(err, sql.ErrNoRows) {
-			return id, repoerrs.ErrNotFound
-		}
-		return id, fmt.Errorf("EmployeeRepo.GetEmployeeOrgRespById - r.Pool.QueryRow: %v", err)
+		return nil, fmt.Errorf("EmployeeRepo.GetEmployeeOrgIdsById - r.Pool.Query: %v", err)
 	}
-	return id, nil
+	defer rows.Close()
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("EmployeeRepo.GetEmployeeOrgIdsById - pgx.CollectRows: %v", err)
+	}
+	return ids, nil
+}
+
+// CountOrganizationResponsibles counts the active employees responsible for
+// the organization. Deactivated employees keep their membership rows but can
+// no longer act for it.
+func (r *EmployeeRepo) CountOrganizationResponsibles(ctx context.Context, organizationId uuid.UUID) (int, error) {
+	request := `SELECT COUNT(*)
+				FROM organization_responsible AS r
+						 JOIN employee AS e ON e.id = r.user_id
+				WHERE r.organization_id = $1
+				  AND e.deactivated_at IS NULL`
+	var count int
+	err := r.Pool.QueryRow(ctx, request, organizationId).Scan(&count)
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return 0, fmt.Errorf("EmployeeRepo.CountOrganizationResponsibles - r.Pool.QueryRow: %v", err)
+	}
+	return count, nil
 }
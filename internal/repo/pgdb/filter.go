@@ -0,0 +1,75 @@
+package pgdb
+
+import (
+	"avito/internal/entity"
+	"avito/pkg/postgres"
+	"context"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+)
+
+// applyFilter adds the conditions of the filter to a listing query.
+func applyFilter(q squirrel.SelectBuilder, f entity.ListFilter) squirrel.SelectBuilder {
+	if f.OrganizationId != nil {
+		q = q.Where(squirrel.Eq{"organization_id": *f.OrganizationId})
+	}
+	if len(f.Statuses) > 0 {
+		q = q.Where(squirrel.Eq{"status": f.Statuses})
+	}
+	if f.CreatedFrom != nil {
+		q = q.Where(squirrel.GtOrEq{"created_at": *f.CreatedFrom})
+	}
+	if f.CreatedTo != nil {
+		q = q.Where(squirrel.LtOrEq{"created_at": *f.CreatedTo})
+	}
+	if f.UpdatedFrom != nil {
+		q = q.Where(squirrel.GtOrEq{"updated_at": *f.UpdatedFrom})
+	}
+	if f.UpdatedTo != nil {
+		q = q.Where(squirrel.LtOrEq{"updated_at": *f.UpdatedTo})
+	}
+	if f.After != nil {
+		q = applyCursor(q, f.Sort, *f.After)
+	}
+	return q
+}
+
+// applyCursor continues a listing right after the cursor row, mirroring the
+// order applySort produces.
+func applyCursor(q squirrel.SelectBuilder, sort string, c entity.Cursor) squirrel.SelectBuilder {
+	switch sort {
+	case entity.SortByCreatedAt:
+		return q.Where("(created_at > ? OR (created_at = ? AND id > ?))", c.CreatedAt, c.CreatedAt, c.Id)
+	case entity.SortByCreatedAtDesc:
+		return q.Where("(created_at < ? OR (created_at = ? AND id > ?))", c.CreatedAt, c.CreatedAt, c.Id)
+	default:
+		return q.Where("(name > ? OR (name = ? AND id > ?))", c.Name, c.Name, c.Id)
+	}
+}
+
+// applySort orders a listing by one of the fixed sort keys; id breaks ties so
+// pages do not overlap. Relevance needs the search query and is ordered by the
+// caller.
+func applySort(q squirrel.SelectBuilder, sort string) squirrel.SelectBuilder {
+	switch sort {
+	case entity.SortByCreatedAt:
+		return q.OrderBy("created_at", "id")
+	case entity.SortByCreatedAtDesc:
+		return q.OrderBy("created_at DESC", "id")
+	default:
+		return q.OrderBy("name", "id")
+	}
+}
+
+// countRows runs a COUNT(*) query and returns its result.
+func countRows(ctx context.Context, pool postgres.PgxPool, q squirrel.SelectBuilder) (int, error) {
+	request, args, err := q.ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("q.ToSql: %v", err)
+	}
+	var count int
+	if err := pool.QueryRow(ctx, request, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("pool.QueryRow: %v", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,275 @@
+package pgdb
+
+import (
+	"avito/internal/entity"
+	"avito/internal/repo/repoerrs"
+	"avito/pkg/postgres"
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+type OrganizationRepo struct {
+	*postgres.Postgres
+}
+
+func NewOrganizationRepo(pg *postgres.Postgres) *OrganizationRepo {
+	return &OrganizationRepo{pg}
+}
+
+// CreateOrganization creates the organization and makes its creator the first
+// admin in the same transaction, so an organization never exists unmanaged.
+func (r *OrganizationRepo) CreateOrganization(ctx context.Context, name string, description, orgType *string, creatorId uuid.UUID) (*entity.Organization, error) {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("OrganizationRepo.CreateOrganization - r.Pool.Begin: %v", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	request := `INSERT INTO organization (name, description, type)
+				VALUES 
+				    ($1, $2, $3)
+				RETURNING *`
+	rows, err := tx.Query(ctx, request, name, description, orgType)
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("OrganizationRepo.CreateOrganization - tx.Query: %v", err)
+	}
+	o, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[entity.Organization])
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("OrganizationRepo.CreateOrganization - pgx.CollectOneRow: %v", err)
+	}
+
+	responsibleReq := `INSERT INTO organization_responsible (organization_id, user_id, role)
+					   VALUES
+					       ($1, $2, $3)`
+	if _, err := tx.Exec(ctx, responsibleReq, o.Id, creatorId, entity.RoleAdmin); err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("OrganizationRepo.CreateOrganization - AddAdmin - tx.Exec: %v", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("OrganizationRepo.CreateOrganization - tx.Commit: %v", err)
+	}
+	return &o, nil
+}
+
+func (r *OrganizationRepo) GetOrganizations(ctx context.Context, limit, offset int) ([]entity.Organization, error) {
+	request := `SELECT *
+				FROM organization
+				ORDER BY name, id
+				LIMIT $1 OFFSET $2`
+	rows, err := r.Pool.Query(ctx, request, limit, offset)
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("OrganizationRepo.GetOrganizations - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+	organizations, err := pgx.CollectRows(rows, pgx.RowToStructByName[entity.Organization])
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("OrganizationRepo.GetOrganizations - pgx.CollectRows: %v", err)
+	}
+	return organizations, nil
+}
+
+func (r *OrganizationRepo) GetOrganizationById(ctx context.Context, organizationId uuid.UUID) (*entity.Organization, error) {
+	request := `SELECT *
+				FROM organization
+				WHERE id=$1`
+	rows, err := r.Pool.Query(ctx, request, organizationId)
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("OrganizationRepo.GetOrganizationById - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+	o, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[entity.Organization])
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, repoerrs.ErrNotFound
+	}
+	return &o, nil
+}
+
+// UpdateOrganization overwrites only the fields that are not nil.
+func (r *OrganizationRepo) UpdateOrganization(ctx context.Context, organizationId uuid.UUID, name, description, orgType *string) (*entity.Organization, error) {
+	request := `UPDATE organization
+				SET name=COALESCE($2, name),
+				    description=COALESCE($3, description),
+				    type=COALESCE($4::organization_type, type),
+				    updated_at=CURRENT_TIMESTAMP
+				WHERE id=$1
+				RETURNING *`
+	rows, err := r.Pool.Query(ctx, request, organizationId, name, description, orgType)
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("OrganizationRepo.UpdateOrganization - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+	o, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[entity.Organization])
+	if err != nil {
+		log.Debugf("err: %v", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repoerrs.ErrNotFound
+		}
+		return nil, fmt.Errorf("OrganizationRepo.UpdateOrganization - pgx.CollectOneRow: %v", err)
+	}
+	return &o, nil
+}
+
+// DeleteOrganization removes the organization; its responsibles, API keys and
+// tenders go with it through ON DELETE CASCADE.
+func (r *OrganizationRepo) DeleteOrganization(ctx context.Context, organizationId uuid.UUID) error {
+	tag, err := r.Pool.Exec(ctx, "DELETE FROM organization WHERE id=$1", organizationId)
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return fmt.Errorf("OrganizationRepo.DeleteOrganization - r.Pool.Exec: %v", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return repoerrs.ErrNotFound
+	}
+	return nil
+}
+
+func (r *OrganizationRepo) GetResponsibles(ctx context.Context, organizationId uuid.UUID) ([]entity.OrganizationResponsible, error) {
+	request := `SELECT id, organization_id, user_id, role
+				FROM organization_responsible
+				WHERE organization_id=$1
+				ORDER BY role, user_id`
+	rows, err := r.Pool.Query(ctx, request, organizationId)
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("OrganizationRepo.GetResponsibles - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+	responsibles, err := pgx.CollectRows(rows, pgx.RowToStructByName[entity.OrganizationResponsible])
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("OrganizationRepo.GetResponsibles - pgx.CollectRows: %v", err)
+	}
+	return responsibles, nil
+}
+
+func (r *OrganizationRepo) GetResponsibleRole(ctx context.Context, organizationId, userId uuid.UUID) (string, error) {
+	request := `SELECT role
+				FROM organization_responsible
+				WHERE organization_id=$1 AND user_id=$2`
+	var role string
+	err := r.Pool.QueryRow(ctx, request, organizationId, userId).Scan(&role)
+	if err != nil {
+		log.Debugf("err: %v", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", repoerrs.ErrNotFound
+		}
+		return "", fmt.Errorf("OrganizationRepo.GetResponsibleRole - r.Pool.QueryRow: %v", err)
+	}
+	return role, nil
+}
+
+// PutResponsible adds the employee to the organization or changes their role.
+// Demoting the last admin returns repoerrs.ErrLastAdmin, and an unknown
+// organization or employee returns repoerrs.ErrNotFound.
+func (r *OrganizationRepo) PutResponsible(ctx context.Context, organizationId, userId uuid.UUID, role string) (*entity.OrganizationResponsible, error) {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("OrganizationRepo.PutResponsible - r.Pool.Begin: %v", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if role != entity.RoleAdmin {
+		if err := r.ensureOtherAdmin(ctx, tx, organizationId, userId); err != nil {
+			return nil, err
+		}
+	}
+	request := `INSERT INTO organization_responsible (organization_id, user_id, role)
+				VALUES 
+				    ($1, $2, $3)
+				ON CONFLICT (organization_id, user_id) DO UPDATE SET role=EXCLUDED.role
+				RETURNING id, organization_id, user_id, role`
+	rows, err := tx.Query(ctx, request, organizationId, userId, role)
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("OrganizationRepo.PutResponsible - tx.Query: %v", err)
+	}
+	resp, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[entity.OrganizationResponsible])
+	if err != nil {
+		log.Debugf("err: %v", err)
+		if isForeignKeyViolation(err) {
+			return nil, repoerrs.ErrNotFound
+		}
+		return nil, fmt.Errorf("OrganizationRepo.PutResponsible - pgx.CollectOneRow: %v", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("OrganizationRepo.PutResponsible - tx.Commit: %v", err)
+	}
+	return &resp, nil
+}
+
+// RemoveResponsible removes the employee from the organization unless they are
+// its last admin.
+func (r *OrganizationRepo) RemoveResponsible(ctx context.Context, organizationId, userId uuid.UUID) error {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return fmt.Errorf("OrganizationRepo.RemoveResponsible - r.Pool.Begin: %v", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := r.ensureOtherAdmin(ctx, tx, organizationId, userId); err != nil {
+		return err
+	}
+	request := `DELETE FROM organization_responsible
+				WHERE organization_id=$1 AND user_id=$2`
+	tag, err := tx.Exec(ctx, request, organizationId, userId)
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return fmt.Errorf("OrganizationRepo.RemoveResponsible - tx.Exec: %v", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return repoerrs.ErrNotFound
+	}
+	if err := tx.Commit(ctx); err != nil {
+		log.Debugf("err: %v", err)
+		return fmt.Errorf("OrganizationRepo.RemoveResponsible - tx.Commit: %v", err)
+	}
+	return nil
+}
+
+// ensureOtherAdmin locks the organization so admin changes are serialized and
+// fails with repoerrs.ErrLastAdmin if userId is its only admin. Deactivated
+// admins are not counted as other admins.
+func (r *OrganizationRepo) ensureOtherAdmin(ctx context.Context, tx pgx.Tx, organizationId, userId uuid.UUID) error {
+	lockReq := `SELECT id
+				FROM organization
+				WHERE id=$1
+				FOR UPDATE`
+	var id uuid.UUID
+	if err := tx.QueryRow(ctx, lockReq, organizationId).Scan(&id); err != nil {
+		log.Debugf("err: %v", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repoerrs.ErrNotFound
+		}
+		return fmt.Errorf("OrganizationRepo.ensureOtherAdmin - LockOrganization - tx.QueryRow: %v", err)
+	}
+	countReq := `SELECT COUNT(*) FILTER (WHERE r.user_id<>$2 AND e.deactivated_at IS NULL),
+						COUNT(*) FILTER (WHERE r.user_id=$2)
+				 FROM organization_responsible AS r
+						  JOIN employee AS e ON e.id = r.user_id
+				 WHERE r.organization_id=$1 AND r.role=$3`
+	var others, self int
+	if err := tx.QueryRow(ctx, countReq, organizationId, userId, entity.RoleAdmin).Scan(&others, &self); err != nil {
+		log.Debugf("err: %v", err)
+		return fmt.Errorf("OrganizationRepo.ensureOtherAdmin - CountAdmins - tx.QueryRow: %v", err)
+	}
+	if self > 0 && others == 0 {
+		return repoerrs.ErrLastAdmin
+	}
+	return nil
+}
@@ -6,10 +6,12 @@ import (
 	"avito/pkg/postgres"
 	"context"
 	"fmt"
+	"github.com/Masterminds/squirrel"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
-	"github.com/lib/pq"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
+	"time"
 )
 
 type TenderRepo struct {
@@ -20,8 +22,45 @@ func NewTenderRepo(pg *postgres.Postgres) *TenderRepo {
 	return &TenderRepo{pg}
 }
 
-func (r *TenderRepo) CreateTender(ctx context.Context, name, description, serviceType string, organisationId uuid.UUID, creatorUsername string) (*entity.Tender, error) {
-	rows, err := r.Pool.Query(ctx, "INSERT INTO tender (name, description, type, organization_id, creator_username) VALUES ($1, $2, $3, $4, $5) RETURNING *", name, description, serviceType, organisationId, creatorUsername)
+// tenderColumns lists every column of tender and tender_current.
+const tenderColumns = `id, name, description, type, status, organization_id, version, creator_username,
+				    created_at, updated_at, edited_by, change_reason, submission_deadline, decision_deadline`
+
+// tenderCurrentInsert copies the versions returned by the inserted CTE into
+// tender_current. Columns are named so the two tables need not share a column
+// order.
+const tenderCurrentInsert = `INSERT INTO tender_current (` + tenderColumns + `)
+				SELECT ` + tenderColumns + ` FROM inserted`
+
+// tenderCurrentUpsert makes the inserted version the current one.
+const tenderCurrentUpsert = `ON CONFLICT (id) DO UPDATE SET
+				    name = EXCLUDED.name,
+				    description = EXCLUDED.description,
+				    type = EXCLUDED.type,
+				    status = EXCLUDED.status,
+				    organization_id = EXCLUDED.organization_id,
+				    version = EXCLUDED.version,
+				    creator_username = EXCLUDED.creator_username,
+				    created_at = EXCLUDED.created_at,
+				    updated_at = EXCLUDED.updated_at,
+				    edited_by = EXCLUDED.edited_by,
+				    change_reason = EXCLUDED.change_reason,
+				    submission_deadline = EXCLUDED.submission_deadline,
+				    decision_deadline = EXCLUDED.decision_deadline`
+
+func (r *TenderRepo) CreateTender(ctx context.Context, name, description, serviceType string, organisationId uuid.UUID, creatorUsername string, submissionDeadline, decisionDeadline *time.Time) (*entity.Tender, error) {
+	request := `WITH inserted AS (
+					INSERT INTO tender (name, description, type, organization_id, creator_username, submission_deadline, decision_deadline)
+					VALUES ($1, $2, $3, $4, $5, $6, $7)
+					RETURNING *
+				)
+				` + tenderCurrentInsert + `
+				RETURNING *`
+	rows, err := r.Pool.Query(ctx, request, name, description, serviceType, organisationId, creatorUsername, submissionDeadline, decisionDeadline)
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("TenderRepo.CreateTender - r.Pool.Query: %v", err)
+	}
 	defer rows.Close()
 	t, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[entity.Tender])
 	if err != nil {
@@ -31,70 +70,106 @@ func (r *TenderRepo) CreateTender(ctx context.Context, name, description, servic
 	return &t, nil
 }
 
-func (r *TenderRepo) GetMyTenders(ctx context.Context, username string, limit, offset int) ([]entity.Tender, error) {
-	request := `SELECT *
-				FROM tender
-				WHERE creator_username=$1 AND version = (SELECT MAX(version)
-                	FROM tender AS t
-                	WHERE t.id = tender.id)
-				ORDER BY name
-				LIMIT $2
-				OFFSET $3`
-	rows, err := r.Pool.Query(ctx, request, username, limit, offset)
+// myTendersQuery selects the latest versions of the tenders created by the
+// employee that match the filter.
+func (r *TenderRepo) myTendersQuery(columns, username string, filter entity.ListFilter) squirrel.SelectBuilder {
+	q := r.Builder.Select(columns).
+		From("tender_current").
+		Where(squirrel.Eq{"creator_username": username})
+	return applyFilter(q, filter)
+}
+
+func (r *TenderRepo) GetMyTenders(ctx context.Context, username string, filter entity.ListFilter, limit, offset int) ([]entity.Tender, error) {
+	q := applySort(r.myTendersQuery("*", username, filter), filter.Sort)
+	request, args, err := q.Limit(uint64(limit)).Offset(uint64(offset)).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("TenderRepo.GetMyTenders - q.ToSql: %v", err)
+	}
+
+	rows, err := r.Pool.Query(ctx, request, args...)
 	if err != nil {
 		log.Debugf("err: %v", err)
 		return nil, fmt.Errorf("TenderRepo.GetMyTenders - r.Pool.Query: %v", err)
 	}
 	defer rows.Close()
-	tenders := make([]entity.Tender, 0)
-	for rows.Next() {
-		var tender entity.Tender
-		err = rows.Scan(&tender.Id, &tender.Name, &tender.Description, &tender.Type, &tender.Status, &tender.OrganizationId, &tender.Version, &tender.CreatorUsername, &tender.CreatedAt, &tender.UpdatedAt)
-		if err != nil {
-			log.Debugf("err: %v", err)
-			return nil, fmt.Errorf("TenderRepo.GetMyTenders - rows.Scan: %v", err)
-		}
-		tenders = append(tenders, tender)
+	tenders, err := pgx.CollectRows(rows, pgx.RowToStructByName[entity.Tender])
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("TenderRepo.GetMyTenders - pgx.CollectRows: %v", err)
 	}
 	return tenders, nil
 }
 
-func (r *TenderRepo) GetTenders(ctx context.Context, serviceTypes []string, limit, offset int) ([]entity.Tender, error) {
-	request := `SELECT *
-				FROM tender
-				WHERE type = ANY($1) AND status='Published'
-				AND version = (SELECT MAX(version)
-                	FROM tender AS t
-                	WHERE t.id = tender.id)
-				ORDER BY name
-				LIMIT $2
-				OFFSET $3;`
+// CountMyTenders counts every tender GetMyTenders can list for the filter,
+// ignoring its cursor.
+func (r *TenderRepo) CountMyTenders(ctx context.Context, username string, filter entity.ListFilter) (int, error) {
+	filter.After = nil
+	count, err := countRows(ctx, r.Pool, r.myTendersQuery("COUNT(*)", username, filter))
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return 0, fmt.Errorf("TenderRepo.CountMyTenders - countRows: %v", err)
+	}
+	return count, nil
+}
 
-	rows, err := r.Pool.Query(ctx, request, pq.Array(serviceTypes), limit, offset)
+// searchQuery matches name and description against both the Russian and
+// English configurations; the expression is the one
+// idx_tender_current_search_gin is built on, so keep them in sync.
+const searchQuery = `(websearch_to_tsquery('russian', ?) || websearch_to_tsquery('english', ?))`
+
+// tendersQuery selects the latest versions of published tenders that match the
+// service types, the search and the filter.
+func (r *TenderRepo) tendersQuery(columns string, serviceTypes []string, search string, filter entity.ListFilter) squirrel.SelectBuilder {
+	q := r.Builder.Select(columns).
+		From("tender_current").
+		Where(squirrel.Eq{"type": serviceTypes, "status": "Published"})
+	q = applyFilter(q, filter)
+	if search != "" {
+		q = q.Where("tender_search_vector(name, description) @@ "+searchQuery, search, search)
+	}
+	return q
+}
+
+func (r *TenderRepo) GetTenders(ctx context.Context, serviceTypes []string, search string, filter entity.ListFilter, limit, offset int) ([]entity.Tender, error) {
+	q := r.tendersQuery("*", serviceTypes, search, filter)
+	if search != "" && filter.Sort == entity.SortByRelevance {
+		q = q.OrderByClause("ts_rank(tender_search_vector(name, description), "+searchQuery+") DESC", search, search)
+	}
+	request, args, err := applySort(q, filter.Sort).Limit(uint64(limit)).Offset(uint64(offset)).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("TenderRepo.GetTenders - q.ToSql: %v", err)
+	}
+
+	rows, err := r.Pool.Query(ctx, request, args...)
 	if err != nil {
 		log.Debugf("err: %v", err)
 		return nil, fmt.Errorf("TenderRepo.GetTenders - r.Pool.Query: %v", err)
 	}
 	defer rows.Close()
-	tenders := make([]entity.Tender, 0)
-	for rows.Next() {
-		var tender entity.Tender
-		err = rows.Scan(&tender.Id, &tender.Name, &tender.Description, &tender.Type, &tender.Status, &tender.OrganizationId, &tender.Version, &tender.CreatorUsername, &tender.CreatedAt, &tender.UpdatedAt)
-		if err != nil {
-			log.Debugf("err: %v", err)
-			return nil, fmt.Errorf("TenderRepo.GetTenders - rows.Scan: %v", err)
-		}
-		tenders = append(tenders, tender)
+	tenders, err := pgx.CollectRows(rows, pgx.RowToStructByName[entity.Tender])
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("TenderRepo.GetTenders - pgx.CollectRows: %v", err)
 	}
 	return tenders, nil
 }
 
+// CountTenders counts every tender GetTenders can list for the arguments,
+// ignoring the cursor of the filter.
+func (r *TenderRepo) CountTenders(ctx context.Context, serviceTypes []string, search string, filter entity.ListFilter) (int, error) {
+	filter.After = nil
+	count, err := countRows(ctx, r.Pool, r.tendersQuery("COUNT(*)", serviceTypes, search, filter))
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return 0, fmt.Errorf("TenderRepo.CountTenders - countRows: %v", err)
+	}
+	return count, nil
+}
+
 func (r *TenderRepo) GetTenderById(ctx context.Context, tenderId uuid.UUID) (*entity.Tender, error) {
-	request := `SELECT * 
-				FROM tender 
-				WHERE id = $1 AND version = (SELECT MAX(version)
-                	FROM tender AS t
-                	WHERE t.id = tender.id)`
+	request := `SELECT *
+				FROM tender_current
+				WHERE id = $1`
 
 	rows, err := r.Pool.Query(ctx, request, tenderId)
 	if err != nil {
@@ -110,107 +185,304 @@ func (r *TenderRepo) GetTenderById(ctx context.Context, tenderId uuid.UUID) (*en
 	return &t, nil
 }
 
-func (r *TenderRepo) PutStatus(ctx context.Context, tenderId uuid.UUID, status string) (*entity.Tender, error) {
-	request := `UPDATE tender 
-				SET status=$1 
-				WHERE id=$2 AND version = (SELECT MAX(version)
-                	FROM tender AS t
-                	WHERE t.id = tender.id)
-                RETURNING *`
-	rows, err := r.Pool.Query(ctx, request, status, tenderId)
+// PutStatus records the status change as a new version of the tender, so the
+// history shows when the tender was published or closed. The locked tender must
+// still be in fromStatus, the status the transition was validated against,
+// otherwise repoerrs.ErrStatusMismatch is returned.
+func (r *TenderRepo) PutStatus(ctx context.Context, tenderId uuid.UUID, fromStatus, status string, editedBy uuid.UUID) (*entity.Tender, error) {
+	tx, err := r.Pool.Begin(ctx)
 	if err != nil {
 		log.Debugf("err: %v", err)
-		return nil, fmt.Errorf("TenderRepo.PutStatus - r.Pool.Query: %v", err)
+		return nil, fmt.Errorf("TenderRepo.PutStatus - r.Pool.Begin: %v", err)
 	}
-	defer rows.Close()
-	t, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[entity.Tender])
+	defer func() { _ = tx.Rollback(ctx) }()
 
+	t, err := r.lockLatest(ctx, tx, tenderId)
 	if err != nil {
+		return nil, err
+	}
+	if t.Status != fromStatus {
+		return nil, repoerrs.ErrStatusMismatch
+	}
+	t.Status = status
+	t.Version++
+	t.EditedBy = &editedBy
+	t.ChangeReason = nil
+	result, err := r.insertVersion(ctx, tx, t)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
 		log.Debugf("err: %v", err)
-		return nil, fmt.Errorf("TenderRepo.PutTenderById - r.Pool.Query: %v", err)
+		return nil, fmt.Errorf("TenderRepo.PutStatus - tx.Commit: %v", err)
 	}
-	return &t, nil
+	return result, nil
 }
 
-func (r *TenderRepo) EditTender(ctx context.Context, tenderId uuid.UUID, name, description, serviceType string) (*entity.Tender, error) {
-	prevVReq := `SELECT *
-				 FROM tender
-			     WHERE id=$1 AND version = (SELECT MAX(version)
-				 FROM tender AS t
-				 WHERE t.id = tender.id)
-				 `
-	rows, err := r.Pool.Query(ctx, prevVReq, tenderId)
-	defer rows.Close()
+// lockLatest returns the latest version of the tender and locks its current row
+// until the end of the transaction, so concurrent writers queue up behind each
+// other. New versions are always inserted as latest.Version+1, so a writer that
+// still raced ahead surfaces as a unique violation instead of a version built
+// on stale data.
+func (r *TenderRepo) lockLatest(ctx context.Context, tx pgx.Tx, tenderId uuid.UUID) (entity.Tender, error) {
+	request := `SELECT *
+				FROM tender_current
+				WHERE id=$1
+				FOR UPDATE`
+	rows, err := tx.Query(ctx, request, tenderId)
 	if err != nil {
 		log.Debugf("err: %v", err)
-		return nil, fmt.Errorf("TenderRepo.EditTender - GetPrevVersion - r.Pool.Query: %v", err)
+		return entity.Tender{}, fmt.Errorf("TenderRepo.lockLatest - tx.Query: %v", err)
 	}
 	t, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[entity.Tender])
 	if err != nil {
 		log.Debugf("err: %v", err)
-		return nil, repoerrs.ErrNotFound
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.Tender{}, repoerrs.ErrNotFound
+		}
+		return entity.Tender{}, fmt.Errorf("TenderRepo.lockLatest - pgx.CollectOneRow: %v", err)
 	}
-	request := `INSERT INTO tender (id, name, description, type, organization_id, creator_username, status, version)
-				VALUES 
-				    ($1, $2, $3, $4, $5, $6, $7, (
-				    	(SELECT COALESCE(MAX(version), 0) + 1
-				    	 FROM tender AS t
-				     	WHERE t.id = $1)))
+	return t, nil
+}
+
+// insertVersion appends the version to the history and makes it current.
+func (r *TenderRepo) insertVersion(ctx context.Context, tx pgx.Tx, t entity.Tender) (*entity.Tender, error) {
+	request := `WITH inserted AS (
+					INSERT INTO tender (id, name, description, type, organization_id, creator_username, status, version, created_at, edited_by, change_reason, submission_deadline, decision_deadline)
+					VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+					RETURNING *
+				)
+				` + tenderCurrentInsert + `
+				` + tenderCurrentUpsert + `
 				RETURNING *`
-	if name == "" {
-		name = t.Name
+	rows, err := tx.Query(ctx, request, t.Id, t.Name, t.Description, t.Type, t.OrganizationId, t.CreatorUsername, t.Status, t.Version, t.CreatedAt, t.EditedBy, t.ChangeReason, t.SubmissionDeadline, t.DecisionDeadline)
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("TenderRepo.insertVersion - tx.Query: %v", err)
 	}
-	if description == "" {
-		description = t.Description
+	t, err = pgx.CollectOneRow(rows, pgx.RowToStructByName[entity.Tender])
+	if err != nil {
+		log.Debugf("err: %v", err)
+		if isUniqueViolation(err) {
+			return nil, repoerrs.ErrConflict
+		}
+		return nil, fmt.Errorf("TenderRepo.insertVersion - pgx.CollectOneRow: %v", err)
 	}
-	if serviceType == "" {
-		serviceType = t.Type
+	return &t, nil
+}
+
+// nullableString stores an empty change reason as NULL.
+func nullableString(s string) *string {
+	if s == "" {
+		return nil
 	}
-	result, err := r.Pool.Query(ctx, request, t.Id, name, description, serviceType, t.OrganizationId, t.CreatorUsername, t.Status)
-	defer result.Close()
-	t, err = pgx.CollectOneRow(result, pgx.RowToStructByName[entity.Tender])
+	return &s
+}
+
+// EditTender creates a new version of the tender. A non-zero expectedVersion
+// must match the latest version, otherwise repoerrs.ErrVersionMismatch is
+// returned. Nil deadlines are kept as they are.
+func (r *TenderRepo) EditTender(ctx context.Context, tenderId uuid.UUID, expectedVersion int, name, description, serviceType string, submissionDeadline, decisionDeadline *time.Time, editedBy uuid.UUID, reason string) (*entity.Tender, error) {
+	tx, err := r.Pool.Begin(ctx)
 	if err != nil {
 		log.Debugf("err: %v", err)
-		return nil, fmt.Errorf("TenderRepo.EditTender - r.Pool.Query: %v", err)
+		return nil, fmt.Errorf("TenderRepo.EditTender - r.Pool.Begin: %v", err)
 	}
-	return &t, nil
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	t, err := r.lockLatest(ctx, tx, tenderId)
+	if err != nil {
+		return nil, err
+	}
+	if expectedVersion != 0 && t.Version != expectedVersion {
+		return nil, repoerrs.ErrVersionMismatch
+	}
+	if name != "" {
+		t.Name = name
+	}
+	if description != "" {
+		t.Description = description
+	}
+	if serviceType != "" {
+		t.Type = serviceType
+	}
+	if submissionDeadline != nil {
+		t.SubmissionDeadline = submissionDeadline
+	}
+	if decisionDeadline != nil {
+		t.DecisionDeadline = decisionDeadline
+	}
+	t.Version++
+	t.EditedBy = &editedBy
+	t.ChangeReason = nullableString(reason)
+	result, err := r.insertVersion(ctx, tx, t)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("TenderRepo.EditTender - tx.Commit: %v", err)
+	}
+	return result, nil
 }
 
-func (r *TenderRepo) RollbackVersion(ctx context.Context, tenderId uuid.UUID, version int) (*entity.Tender, error) {
+// RollbackVersion restores the name, description and service type of the given
+// version as a new version. The status is not rolled back: it only changes
+// through PutStatus, so a closed tender stays closed.
+func (r *TenderRepo) RollbackVersion(ctx context.Context, tenderId uuid.UUID, version int, editedBy uuid.UUID, reason string) (*entity.Tender, error) {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("TenderRepo.RollbackVersion - r.Pool.Begin: %v", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	latest, err := r.lockLatest(ctx, tx, tenderId)
+	if err != nil {
+		return nil, err
+	}
 	prevVReq := `SELECT *
 				 FROM tender
 			     WHERE id=$1 AND version = $2
 				 `
-	rows, err := r.Pool.Query(ctx, prevVReq, tenderId, version)
-	defer rows.Close()
+	rows, err := tx.Query(ctx, prevVReq, tenderId, version)
 	if err != nil {
 		log.Debugf("err: %v", err)
-		return nil, fmt.Errorf("TenderRepo.EditTender - GetPrevVersion - r.Pool.Query: %v", err)
+		return nil, fmt.Errorf("TenderRepo.RollbackVersion - GetPrevVersion - tx.Query: %v", err)
 	}
 	t, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[entity.Tender])
 	if err != nil {
 		log.Debugf("err: %v", err)
+		return nil, repoerrs.ErrVersionNotFound
+	}
+	t.Status = latest.Status
+	t.Version = latest.Version + 1
+	t.EditedBy = &editedBy
+	t.ChangeReason = nullableString(reason)
+	result, err := r.insertVersion(ctx, tx, t)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("TenderRepo.RollbackVersion - tx.Commit: %v", err)
+	}
+	return result, nil
+}
+
+func (r *TenderRepo) GetVersions(ctx context.Context, tenderId uuid.UUID) ([]entity.Tender, error) {
+	request := `SELECT *
+				FROM tender
+				WHERE id=$1
+				ORDER BY version`
+	rows, err := r.Pool.Query(ctx, request, tenderId)
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("TenderRepo.GetVersions - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+	tenders, err := pgx.CollectRows(rows, pgx.RowToStructByName[entity.Tender])
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("TenderRepo.GetVersions - pgx.CollectRows: %v", err)
+	}
+	if len(tenders) == 0 {
 		return nil, repoerrs.ErrNotFound
 	}
-	lastVReq := `SELECT MAX(version)
+	return tenders, nil
+}
+
+func (r *TenderRepo) GetVersion(ctx context.Context, tenderId uuid.UUID, version int) (*entity.Tender, error) {
+	request := `SELECT *
 				FROM tender
-				WHERE id=$1`
-	var lastV int
-	if err := r.Pool.QueryRow(ctx, lastVReq, tenderId).Scan(&lastV); err != nil {
+				WHERE id=$1 AND version=$2`
+	rows, err := r.Pool.Query(ctx, request, tenderId, version)
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("TenderRepo.GetVersion - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+	t, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[entity.Tender])
+	if err != nil {
 		log.Debugf("err: %v", err)
 		return nil, repoerrs.ErrVersionNotFound
 	}
-	request := `INSERT INTO tender (id, name, description, type, organization_id, creator_username, status, version)
-				VALUES 
-				    ($1, $2, $3, $4, $5, $6, $7, $8)
-				RETURNING *`
+	return &t, nil
+}
 
-	result, err := r.Pool.Query(ctx, request, t.Id, t.Name, t.Description, t.Type, t.OrganizationId, t.CreatorUsername, t.Status, lastV+1)
-	defer result.Close()
-	t, err = pgx.CollectOneRow(result, pgx.RowToStructByName[entity.Tender])
+// CloseExpiredTenders closes up to limit tenders whose decision deadline
+// passed before now and cancels their bids that have no decision yet, all as
+// new versions made by no employee. Tenders locked by another writer are skipped until the
+// next call. It returns the ids of the closed tenders.
+func (r *TenderRepo) CloseExpiredTenders(ctx context.Context, now time.Time, limit int, reason string) ([]uuid.UUID, error) {
+	tx, err := r.Pool.Begin(ctx)
 	if err != nil {
 		log.Debugf("err: %v", err)
-		return nil, fmt.Errorf("TenderRepo.RollbackVersion - r.Pool.Query: %v", err)
+		return nil, fmt.Errorf("TenderRepo.CloseExpiredTenders - r.Pool.Begin: %v", err)
 	}
-	return &t, nil
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	closeReq := `WITH expired AS (
+					 SELECT *
+					 FROM tender_current
+					 WHERE status <> 'Closed'
+					   AND decision_deadline <= $1
+					 ORDER BY id
+					 LIMIT $2
+					 FOR UPDATE SKIP LOCKED
+				 ),
+				 inserted AS (
+					 INSERT INTO tender (id, name, description, type, organization_id, creator_username, status, version, created_at, edited_by, change_reason, submission_deadline, decision_deadline)
+					 SELECT id, name, description, type, organization_id, creator_username, 'Closed'::tender_status, version + 1, created_at, NULL::uuid, $3, submission_deadline, decision_deadline
+					 FROM expired
+					 RETURNING *
+				 )
+				 ` + tenderCurrentInsert + `
+				 ` + tenderCurrentUpsert + `
+				 RETURNING id`
+	rows, err := tx.Query(ctx, closeReq, now, limit, reason)
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("TenderRepo.CloseExpiredTenders - CloseTenders - tx.Query: %v", err)
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		log.Debugf("err: %v", err)
+		return nil, fmt.Errorf("TenderRepo.CloseExpiredTenders - CloseTenders - pgx.CollectRows: %v", err)
+	}
+	if len(ids) == 0 {
+		return ids, nil
+	}
+
+	cancelReq := `WITH unfinished AS (
+					  SELECT *
+					  FROM bid_current
+					  WHERE tender_id = ANY($1)
+					    AND status <> 'Canceled'
+					    AND decision IS NULL
+					  FOR UPDATE
+				  ),
+				  inserted AS (
+					  INSERT INTO bid (id, name, description, tender_id, status, decision, author_type, author_id, version, created_at, edited_by, change_reason, organization_id)
+					  SELECT id, name, description, tender_id, 'Canceled'::bid_status, decision, author_type, author_id, version + 1, created_at, NULL::uuid, $2, organization_id
+					  FROM unfinished
+					  RETURNING *
+				  )
+				  ` + bidCurrentInsert + `
+				  ` + bidCurrentUpsert
+	if _, err := tx.Exec(ctx, cancelReq, ids, reason); err != nil {
+		log.Debugf("err: %v", err)
+		if isUniqueViolation(err) {
+			return nil, repoerrs.ErrConflict
+		}
+		return nil, fmt.Errorf("TenderRepo.CloseExpiredTenders - CancelBids - tx.Exec: %v", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		log.Debugf("err: %v", err)
+		if isUniqueViolation(err) {
+			return nil, repoerrs.ErrConflict
+		}
+		return nil, fmt.Errorf("TenderRepo.CloseExpiredTenders - tx.Commit: %v", err)
+	}
+	return ids, nil
 }
@@ -6,42 +6,84 @@ import (
 	"avito/pkg/postgres"
 	"context"
 	"github.com/google/uuid"
+	"time"
 )
 
 type Tender interface {
-	CreateTender(ctx context.Context, name, description, serviceType string, organisationId uuid.UUID, creatorUsername string) (*entity.Tender, error)
-	GetMyTenders(ctx context.Context, username string, limit, offset int) ([]entity.Tender, error)
-	GetTenders(ctx context.Context, serviceTypes []string, limit, offset int) ([]entity.Tender, error)
+	CreateTender(ctx context.Context, name, description, serviceType string, organisationId uuid.UUID, creatorUsername string, submissionDeadline, decisionDeadline *time.Time) (*entity.Tender, error)
+	GetMyTenders(ctx context.Context, username string, filter entity.ListFilter, limit, offset int) ([]entity.Tender, error)
+	GetTenders(ctx context.Context, serviceTypes []string, search string, filter entity.ListFilter, limit, offset int) ([]entity.Tender, error)
+	CountMyTenders(ctx context.Context, username string, filter entity.ListFilter) (int, error)
+	CountTenders(ctx context.Context, serviceTypes []string, search string, filter entity.ListFilter) (int, error)
 	GetTenderById(ctx context.Context, tenderId uuid.UUID) (*entity.Tender, error)
-	PutStatus(ctx context.Context, tenderId uuid.UUID, status string) (*entity.Tender, error)
-	EditTender(ctx context.Context, tenderId uuid.UUID, name, description, serviceType string) (*entity.Tender, error)
-	RollbackVersion(ctx context.Context, tenderId uuid.UUID, version int) (*entity.Tender, error)
+	PutStatus(ctx context.Context, tenderId uuid.UUID, fromStatus, status string, editedBy uuid.UUID) (*entity.Tender, error)
+	EditTender(ctx context.Context, tenderId uuid.UUID, expectedVersion int, name, description, serviceType string, submissionDeadline, decisionDeadline *time.Time, editedBy uuid.UUID, reason string) (*entity.Tender, error)
+	RollbackVersion(ctx context.Context, tenderId uuid.UUID, version int, editedBy uuid.UUID, reason string) (*entity.Tender, error)
+	GetVersions(ctx context.Context, tenderId uuid.UUID) ([]entity.Tender, error)
+	GetVersion(ctx context.Context, tenderId uuid.UUID, version int) (*entity.Tender, error)
+	CloseExpiredTenders(ctx context.Context, now time.Time, limit int, reason string) ([]uuid.UUID, error)
 }
 
 type Employee interface {
 	GetEmployeeIdByUsername(ctx context.Context, username string) (uuid.UUID, error)
 	GetEmployeeById(ctx context.Context, id uuid.UUID) (*entity.Employee, error)
-	GetEmployeeOrgIdById(ctx context.Context, employeeId uuid.UUID) (uuid.UUID, error)
+	GetEmployeeByUsername(ctx context.Context, username string) (*entity.Employee, error)
+	CreateEmployee(ctx context.Context, username string, firstName, lastName *string) (*entity.Employee, error)
+	UpdateEmployeeNames(ctx context.Context, id uuid.UUID, firstName, lastName *string) (*entity.Employee, error)
+	DeactivateEmployee(ctx context.Context, id uuid.UUID) (*entity.Employee, error)
+	GetEmployeeOrgIdsById(ctx context.Context, employeeId uuid.UUID) ([]uuid.UUID, error)
+	CountOrganizationResponsibles(ctx context.Context, organizationId uuid.UUID) (int, error)
 }
 type Bid interface {
-	CreateBid(ctx context.Context, name, description string, tenderId uuid.UUID, authorType string, authorId uuid.UUID) (*entity.Bid, error)
-	GetMyBids(ctx context.Context, authorId uuid.UUID, limit, offset int) ([]entity.Bid, error)
+	CreateBid(ctx context.Context, name, description string, tenderId uuid.UUID, authorType string, authorId uuid.UUID, organizationId *uuid.UUID) (*entity.Bid, error)
+	GetMyBids(ctx context.Context, authorId uuid.UUID, filter entity.ListFilter, limit, offset int) ([]entity.Bid, error)
+	CountMyBids(ctx context.Context, authorId uuid.UUID, filter entity.ListFilter) (int, error)
+	GetBidsForTender(ctx context.Context, tenderId, employeeId uuid.UUID, withPublished bool, filter entity.ListFilter, limit, offset int) ([]entity.Bid, error)
 	GetBidById(ctx context.Context, bidId uuid.UUID) (*entity.Bid, error)
-	PutStatus(ctx context.Context, BidId uuid.UUID, status string) (*entity.Bid, error)
-	EditBid(ctx context.Context, bidId uuid.UUID, name, description string) (*entity.Bid, error)
-	RollbackVersion(ctx context.Context, bidId uuid.UUID, version int) (*entity.Bid, error)
+	PutStatus(ctx context.Context, bidId uuid.UUID, fromStatus, status string, editedBy uuid.UUID) (*entity.Bid, error)
+	EditBid(ctx context.Context, bidId uuid.UUID, expectedVersion int, name, description string, editedBy uuid.UUID, reason string) (*entity.Bid, error)
+	RollbackVersion(ctx context.Context, bidId uuid.UUID, version int, editedBy uuid.UUID, reason string) (*entity.Bid, error)
+	GetVersions(ctx context.Context, bidId uuid.UUID) ([]entity.Bid, error)
+	GetVersion(ctx context.Context, bidId uuid.UUID, version int) (*entity.Bid, error)
+	SubmitDecision(ctx context.Context, tenderId, bidId, employeeId uuid.UUID, decision string, quorum int, closeReason string) (*entity.Bid, error)
+	CreateFeedback(ctx context.Context, bidId, employeeId uuid.UUID, description string) (*entity.BidFeedback, error)
+	GetAuthorFeedback(ctx context.Context, authorId uuid.UUID, limit, offset int) ([]entity.BidFeedback, error)
+	HasAuthorBid(ctx context.Context, tenderId, authorId uuid.UUID) (bool, error)
+}
+
+type ApiKey interface {
+	CreateApiKey(ctx context.Context, organizationId, createdBy uuid.UUID, keyHash string, scopes []string) (*entity.ApiKey, error)
+	GetApiKeys(ctx context.Context, organizationId uuid.UUID) ([]entity.ApiKey, error)
+	GetActiveApiKeyByHash(ctx context.Context, keyHash string) (*entity.ApiKey, error)
+	RevokeApiKey(ctx context.Context, organizationId, keyId uuid.UUID) (*entity.ApiKey, error)
+}
+
+type Organization interface {
+	CreateOrganization(ctx context.Context, name string, description, orgType *string, creatorId uuid.UUID) (*entity.Organization, error)
+	GetOrganizations(ctx context.Context, limit, offset int) ([]entity.Organization, error)
+	GetOrganizationById(ctx context.Context, organizationId uuid.UUID) (*entity.Organization, error)
+	UpdateOrganization(ctx context.Context, organizationId uuid.UUID, name, description, orgType *string) (*entity.Organization, error)
+	DeleteOrganization(ctx context.Context, organizationId uuid.UUID) error
+	GetResponsibles(ctx context.Context, organizationId uuid.UUID) ([]entity.OrganizationResponsible, error)
+	GetResponsibleRole(ctx context.Context, organizationId, userId uuid.UUID) (string, error)
+	PutResponsible(ctx context.Context, organizationId, userId uuid.UUID, role string) (*entity.OrganizationResponsible, error)
+	RemoveResponsible(ctx context.Context, organizationId, userId uuid.UUID) error
 }
 
 type Repositories struct {
 	Tender
 	Employee
 	Bid
+	ApiKey
+	Organization
 }
 
 func NewRepositories(pg *postgres.Postgres) *Repositories {
 	return &Repositories{
-		Tender:   pgdb.NewTenderRepo(pg),
-		Employee: pgdb.NewEmployeeRepo(pg),
-		Bid:      pgdb.NewBidRepo(pg),
+		Tender:       pgdb.NewTenderRepo(pg),
+		Employee:     pgdb.NewEmployeeRepo(pg),
+		Bid:          pgdb.NewBidRepo(pg),
+		ApiKey:       pgdb.NewApiKeyRepo(pg),
+		Organization: pgdb.NewOrganizationRepo(pg),
 	}
 }
@@ -6,4 +6,10 @@ var (
 	ErrNotFound        = errors.New("not found")
 	ErrAlreadyExists   = errors.New("already exists")
 	ErrVersionNotFound = errors.New("version not found")
+	ErrAlreadyDecided  = errors.New("already decided")
+	ErrClosed          = errors.New("closed")
+	ErrConflict        = errors.New("conflict")
+	ErrVersionMismatch = errors.New("version mismatch")
+	ErrStatusMismatch  = errors.New("status mismatch")
+	ErrLastAdmin       = errors.New("last admin")
 )
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"avito/internal/controllers/http/formating"
+	"avito/internal/entity"
+	"avito/internal/repo"
+	"avito/internal/repo/repoerrs"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/google/uuid"
+	"slices"
+)
+
+const (
+	ScopeTendersRead  = "tenders:read"
+	ScopeTendersWrite = "tenders:write"
+	ScopeBidsRead     = "bids:read"
+	ScopeBidsWrite    = "bids:write"
+
+	apiKeyPrefix = "ak_"
+	apiKeyBytes  = 32
+)
+
+var apiKeyScopes = []string{
+	ScopeTendersRead,
+	ScopeTendersWrite,
+	ScopeBidsRead,
+	ScopeBidsWrite,
+}
+
+type ApiKeyService struct {
+	apiKeyRepo repo.ApiKey
+}
+
+func NewApiKeyService(apiKeyRepo repo.ApiKey) *ApiKeyService {
+	return &ApiKeyService{apiKeyRepo: apiKeyRepo}
+}
+
+// CreateApiKey generates a key for the organization. Only the hash is stored,
+// so the returned output is the one place the plain key is ever shown.
+func (s *ApiKeyService) CreateApiKey(ctx context.Context, input CreateApiKeyInput) (*CreateApiKeyOutput, error) {
+	if len(input.Scopes) == 0 {
+		return nil, ErrInvalidScope
+	}
+	for _, scope := range input.Scopes {
+		if !slices.Contains(apiKeyScopes, scope) {
+			return nil, ErrInvalidScope
+		}
+	}
+	raw := make([]byte, apiKeyBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, ErrCannotCreateApiKey
+	}
+	key := apiKeyPrefix + hex.EncodeToString(raw)
+	apiKey, err := s.apiKeyRepo.CreateApiKey(ctx, input.OrganizationId, input.CreatedBy, hashApiKey(key), input.Scopes)
+	if err != nil {
+		return nil, ErrCannotCreateApiKey
+	}
+	return &CreateApiKeyOutput{
+		ApiKeyOutput: toApiKeyOutput(apiKey),
+		Key:          key,
+	}, nil
+}
+
+func (s *ApiKeyService) GetApiKeys(ctx context.Context, organizationId uuid.UUID) ([]ApiKeyOutput, error) {
+	keys, err := s.apiKeyRepo.GetApiKeys(ctx, organizationId)
+	if err != nil {
+		return nil, ErrCannotGetApiKeys
+	}
+	output := make([]ApiKeyOutput, len(keys))
+	for i := range keys {
+		output[i] = toApiKeyOutput(&keys[i])
+	}
+	return output, nil
+}
+
+func (s *ApiKeyService) RevokeApiKey(ctx context.Context, organizationId, keyId uuid.UUID) (*ApiKeyOutput, error) {
+	apiKey, err := s.apiKeyRepo.RevokeApiKey(ctx, organizationId, keyId)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return nil, ErrApiKeyNotFound
+		}
+		return nil, ErrCannotRevokeApiKey
+	}
+	output := toApiKeyOutput(apiKey)
+	return &output, nil
+}
+
+// Authenticate returns the active key matching the plain key sent by a client.
+func (s *ApiKeyService) Authenticate(ctx context.Context, key string) (*entity.ApiKey, error) {
+	apiKey, err := s.apiKeyRepo.GetActiveApiKeyByHash(ctx, hashApiKey(key))
+	if err != nil {
+		return nil, ErrInvalidApiKey
+	}
+	return apiKey, nil
+}
+
+func hashApiKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func toApiKeyOutput(apiKey *entity.ApiKey) ApiKeyOutput {
+	output := ApiKeyOutput{
+		Id:             apiKey.Id,
+		OrganizationId: apiKey.OrganizationId,
+		Scopes:         apiKey.Scopes,
+		CreatedAt:      apiKey.CreatedAt.Format(formating.TimeFormat),
+	}
+	if apiKey.RevokedAt != nil {
+		revokedAt := apiKey.RevokedAt.Format(formating.TimeFormat)
+		output.RevokedAt = &revokedAt
+	}
+	return output
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// AuthService verifies the bearer tokens issued to employees by the identity
+// provider, which signs them with the same key.
+type AuthService struct {
+	signKey []byte
+}
+
+func NewAuthService(signKey string) *AuthService {
+	return &AuthService{
+		signKey: []byte(signKey),
+	}
+}
+
+// ParseToken verifies the signature and expiry of the token and returns the
+// employee id from its subject claim.
+func (s *AuthService) ParseToken(token string) (uuid.UUID, error) {
+	if len(s.signKey) == 0 {
+		return uuid.Nil, ErrInvalidToken
+	}
+	claims := &jwt.RegisteredClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return s.signKey, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return uuid.Nil, ErrInvalidToken
+	}
+	employeeId, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return uuid.Nil, ErrInvalidToken
+	}
+	return employeeId, nil
+}
@@ -8,21 +8,88 @@ import (
 	"context"
 	"errors"
 	"github.com/google/uuid"
+	"slices"
+	"time"
 )
 
+// decisionQuorum caps the number of approvals a bid needs; organizations with
+// fewer responsibles need approval from all of them.
+const decisionQuorum = 3
+
+// approvedReason is the change reason of the version that closes a tender once
+// a bid reaches quorum.
+const approvedReason = "bid approved"
+
 type BidService struct {
-	bidRepo    repo.Bid
-	tenderRepo repo.Tender
+	bidRepo      repo.Bid
+	tenderRepo   repo.Tender
+	employeeRepo repo.Employee
 }
 
-func NewBidService(bidRepo repo.Bid, tenderRepo repo.Tender) *BidService {
+func NewBidService(bidRepo repo.Bid, tenderRepo repo.Tender, employeeRepo repo.Employee) *BidService {
 	return &BidService{
-		bidRepo:    bidRepo,
-		tenderRepo: tenderRepo,
+		bidRepo:      bidRepo,
+		tenderRepo:   tenderRepo,
+		employeeRepo: employeeRepo,
+	}
+}
+
+// checkTenderOpen verifies that the tender exists, accepts bids until its
+// submission deadline and does not belong to any of the author's own
+// organizations. Lookup failures reject the bid rather than skip a check.
+func (s *BidService) checkTenderOpen(ctx context.Context, tenderId, authorId uuid.UUID) error {
+	tender, err := s.tenderRepo.GetTenderById(ctx, tenderId)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return ErrTenderNotFound
+		}
+		return ErrCannotCheckTender
+	}
+	if tender.Status != "Published" {
+		return ErrTenderNotPublished
+	}
+	if tender.SubmissionDeadline != nil && !time.Now().Before(*tender.SubmissionDeadline) {
+		return ErrSubmissionClosed
+	}
+	authorOrgs, err := s.employeeRepo.GetEmployeeOrgIdsById(ctx, authorId)
+	if err != nil {
+		return ErrCannotCheckTender
+	}
+	if slices.Contains(authorOrgs, tender.OrganizationId) {
+		return ErrOwnTender
+	}
+	return nil
+}
+
+// checkBidOrganization verifies that an organization bid names one of the
+// author's organizations and that a user bid names none.
+func (s *BidService) checkBidOrganization(ctx context.Context, input BidCreateInput) error {
+	if input.AuthorType != "Organization" {
+		if input.OrganizationId != nil {
+			return ErrInvalidBidOrganization
+		}
+		return nil
 	}
+	if input.OrganizationId == nil {
+		return ErrInvalidBidOrganization
+	}
+	authorOrgs, err := s.employeeRepo.GetEmployeeOrgIdsById(ctx, input.AuthorId)
+	if err != nil {
+		return ErrCannotCreateBid
+	}
+	if !slices.Contains(authorOrgs, *input.OrganizationId) {
+		return ErrOrganisationResponsibleNotFound
+	}
+	return nil
 }
 
 func (s *BidService) CreateBid(ctx context.Context, input BidCreateInput) (*entity.Bid, error) {
+	if err := s.checkBidOrganization(ctx, input); err != nil {
+		return nil, err
+	}
+	if err := s.checkTenderOpen(ctx, input.TenderId, input.AuthorId); err != nil {
+		return nil, err
+	}
 	bid, err := s.bidRepo.CreateBid(
 		ctx,
 		input.Name,
@@ -30,6 +97,7 @@ func (s *BidService) CreateBid(ctx context.Context, input BidCreateInput) (*enti
 		input.TenderId,
 		input.AuthorType,
 		input.AuthorId,
+		input.OrganizationId,
 	)
 	if err != nil {
 		return nil, ErrCannotCreateBid
@@ -37,10 +105,64 @@ func (s *BidService) CreateBid(ctx context.Context, input BidCreateInput) (*enti
 	return bid, nil
 }
 
-func (s *BidService) GetMyBids(ctx context.Context, input GetMyBidsInput) ([]GetMyBidsOutput, error) {
+func (s *BidService) GetMyBids(ctx context.Context, input GetMyBidsInput) (*BidsPage, error) {
 	bids, err := s.bidRepo.GetMyBids(
 		ctx,
 		input.AuthorId,
+		input.Filter,
+		input.Limit,
+		input.Offset,
+	)
+	if err != nil {
+		return nil, ErrCannotGetBids
+	}
+	output := make([]GetMyBidsOutput, len(bids))
+	for i, bid := range bids {
+		output[i] = GetMyBidsOutput{
+			Id:         bid.Id,
+			Name:       bid.Name,
+			Status:     bid.Status,
+			AuthorType: bid.AuthorType,
+			AuthorId:   bid.AuthorId,
+			Version:    bid.Version,
+			CreatedAt:  bid.CreatedAt.Format(formating.TimeFormat),
+		}
+	}
+	page := &BidsPage{
+		Bids: output,
+		Next: nextBidCursor(bids, input.Filter.Sort, input.Limit),
+	}
+	if input.WithTotal {
+		total, err := s.bidRepo.CountMyBids(ctx, input.AuthorId, input.Filter)
+		if err != nil {
+			return nil, ErrCannotGetBids
+		}
+		page.Total = &total
+	}
+	return page, nil
+}
+
+// nextBidCursor points after the last bid of a full page.
+func nextBidCursor(bids []entity.Bid, sort string, limit int) *entity.Cursor {
+	if limit == 0 || len(bids) < limit {
+		return nil
+	}
+	last := bids[len(bids)-1]
+	return &entity.Cursor{
+		Sort:      sort,
+		Name:      last.Name,
+		CreatedAt: last.CreatedAt,
+		Id:        last.Id,
+	}
+}
+
+func (s *BidService) GetBidsForTender(ctx context.Context, input GetBidsForTenderInput) ([]GetMyBidsOutput, error) {
+	bids, err := s.bidRepo.GetBidsForTender(
+		ctx,
+		input.TenderId,
+		input.EmployeeId,
+		input.IsResponsible,
+		input.Filter,
 		input.Limit,
 		input.Offset,
 	)
@@ -78,46 +200,99 @@ func (s *BidService) GetStatus(ctx context.Context, input GetBidStatusInput) (st
 	return bid.Status, nil
 }
 
+// PutStatus validates the transition against the current status and retries
+// from a fresh read when the status changed before the write locked the bid.
 func (s *BidService) PutStatus(ctx context.Context, input PutBidStatusInput) (*PutBidStatusOutput, error) {
-	bid, err := s.bidRepo.PutStatus(ctx, input.BidId, input.Status)
+	var bid *entity.Bid
+	var err error
+	for attempt := 0; attempt < versionRetries; attempt++ {
+		bid, err = s.bidRepo.GetBidById(ctx, input.BidId)
+		if err != nil {
+			return nil, ErrBidNotFound
+		}
+		if !canTransit(bidTransitions, bid.Status, input.Status) {
+			return nil, ErrInvalidTransition
+		}
+		bid, err = s.bidRepo.PutStatus(ctx, input.BidId, bid.Status, input.Status, input.EditedBy)
+		if !errors.Is(err, repoerrs.ErrConflict) && !errors.Is(err, repoerrs.ErrStatusMismatch) {
+			break
+		}
+	}
 	if err != nil {
 		if errors.Is(err, repoerrs.ErrNotFound) {
 			return nil, ErrBidNotFound
 		}
+		if errors.Is(err, repoerrs.ErrConflict) || errors.Is(err, repoerrs.ErrStatusMismatch) {
+			return nil, ErrVersionConflict
+		}
 		return nil, ErrCannotPutStatus
 	}
 	return &PutBidStatusOutput{
-		Id:         bid.Id,
-		Name:       bid.Name,
-		Status:     bid.Status,
-		AuthorType: bid.AuthorType,
-		AuthorId:   bid.AuthorId,
-		Version:    bid.Version,
-		CreatedAt:  bid.CreatedAt,
+		Id:           bid.Id,
+		Name:         bid.Name,
+		Status:       bid.Status,
+		AuthorType:   bid.AuthorType,
+		AuthorId:     bid.AuthorId,
+		Version:      bid.Version,
+		CreatedAt:    bid.CreatedAt,
+		EditedBy:     bid.EditedBy,
+		ChangeReason: bid.ChangeReason,
 	}, nil
 }
 
 func (s *BidService) EditBid(ctx context.Context, input EditBidInput) (*EditBidOutput, error) {
-	bid, err := s.bidRepo.EditBid(ctx, input.Id, input.Name, input.Description)
+	bid, err := s.bidRepo.GetBidById(ctx, input.Id)
+	if err != nil {
+		return nil, ErrBidNotFound
+	}
+	if err := s.checkTenderOpen(ctx, bid.TenderId, bid.AuthorId); err != nil {
+		return nil, err
+	}
+	for attempt := 0; attempt < versionRetries; attempt++ {
+		bid, err = s.bidRepo.EditBid(ctx, input.Id, input.ExpectedVersion, input.Name, input.Description, input.EditedBy, input.Reason)
+		if !errors.Is(err, repoerrs.ErrConflict) {
+			break
+		}
+	}
 	if err != nil {
 		if errors.Is(err, repoerrs.ErrNotFound) {
 			return nil, ErrBidNotFound
 		}
+		if errors.Is(err, repoerrs.ErrVersionMismatch) {
+			return nil, ErrVersionMismatch
+		}
+		if errors.Is(err, repoerrs.ErrConflict) {
+			return nil, ErrVersionConflict
+		}
 		return nil, ErrCannotEditBid
 	}
 	return &EditBidOutput{
-		Id:         bid.Id,
-		Name:       bid.Name,
-		Status:     bid.Status,
-		AuthorType: bid.AuthorType,
-		AuthorId:   bid.AuthorId,
-		Version:    bid.Version,
-		CreatedAt:  bid.CreatedAt,
+		Id:           bid.Id,
+		Name:         bid.Name,
+		Status:       bid.Status,
+		AuthorType:   bid.AuthorType,
+		AuthorId:     bid.AuthorId,
+		Version:      bid.Version,
+		CreatedAt:    bid.CreatedAt,
+		EditedBy:     bid.EditedBy,
+		ChangeReason: bid.ChangeReason,
 	}, nil
 }
 
 func (s *BidService) RollbackVersion(ctx context.Context, input RollbackVersionInput) (*RollbackBidVersionOutput, error) {
-	bid, err := s.bidRepo.RollbackVersion(ctx, input.Id, input.Version)
+	bid, err := s.bidRepo.GetBidById(ctx, input.Id)
+	if err != nil {
+		return nil, ErrBidNotFound
+	}
+	if isFinal(bidTransitions, bid.Status) {
+		return nil, ErrInvalidTransition
+	}
+	for attempt := 0; attempt < versionRetries; attempt++ {
+		bid, err = s.bidRepo.RollbackVersion(ctx, input.Id, input.Version, input.EditedBy, input.Reason)
+		if !errors.Is(err, repoerrs.ErrConflict) {
+			break
+		}
+	}
 	if err != nil {
 		if errors.Is(err, repoerrs.ErrNotFound) {
 			return nil, ErrBidNotFound
@@ -125,34 +300,160 @@ func (s *BidService) RollbackVersion(ctx context.Context, input RollbackVersionI
 		if errors.Is(err, repoerrs.ErrVersionNotFound) {
 			return nil, ErrVersionNotFound
 		}
+		if errors.Is(err, repoerrs.ErrConflict) {
+			return nil, ErrVersionConflict
+		}
+		return nil, ErrCannotRollback
 	}
 	return &RollbackBidVersionOutput{
-		Id:         bid.Id,
-		Name:       bid.Name,
-		Status:     bid.Status,
-		AuthorType: bid.AuthorType,
-		AuthorId:   bid.AuthorId,
-		Version:    bid.Version,
-		CreatedAt:  bid.CreatedAt,
+		Id:           bid.Id,
+		Name:         bid.Name,
+		Status:       bid.Status,
+		AuthorType:   bid.AuthorType,
+		AuthorId:     bid.AuthorId,
+		Version:      bid.Version,
+		CreatedAt:    bid.CreatedAt,
+		EditedBy:     bid.EditedBy,
+		ChangeReason: bid.ChangeReason,
 	}, nil
 }
 
-func (s *BidService) SubmitDecision(ctx context.Context, tenderId, bidId uuid.UUID, decision string) (*entity.Bid, error) {
-	if decision == "Approved" {
-		bid, err := s.bidRepo.GetBidById(ctx, bidId)
-		if err != nil {
+// checkDecidable verifies that the tender is published and the bid is a
+// published bid on it, the only state in which responsibles may decide on the
+// bid or leave feedback.
+func (s *BidService) checkDecidable(ctx context.Context, tenderId, bidId uuid.UUID) (*entity.Tender, *entity.Bid, error) {
+	tender, err := s.tenderRepo.GetTenderById(ctx, tenderId)
+	if err != nil {
+		return nil, nil, ErrTenderNotFound
+	}
+	if tender.Status != "Published" {
+		return nil, nil, ErrInvalidTransition
+	}
+	bid, err := s.bidRepo.GetBidById(ctx, bidId)
+	if err != nil || bid.TenderId != tenderId {
+		return nil, nil, ErrBidNotFound
+	}
+	if bid.Status != "Published" {
+		return nil, nil, ErrInvalidTransition
+	}
+	return tender, bid, nil
+}
+
+func (s *BidService) SubmitDecision(ctx context.Context, input SubmitDecisionInput) (*entity.Bid, error) {
+	tender, _, err := s.checkDecidable(ctx, input.TenderId, input.BidId)
+	if err != nil {
+		return nil, err
+	}
+	responsibles, err := s.employeeRepo.CountOrganizationResponsibles(ctx, tender.OrganizationId)
+	if err != nil {
+		return nil, ErrCannotSubmitDecision
+	}
+	bid, err := s.bidRepo.SubmitDecision(ctx, input.TenderId, input.BidId, input.EmployeeId, input.Decision, min(decisionQuorum, responsibles), approvedReason)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
 			return nil, ErrBidNotFound
 		}
-		_, err = s.tenderRepo.PutStatus(ctx, tenderId, "Closed")
-		if err != nil {
-			return nil, ErrTenderNotFound
+		if errors.Is(err, repoerrs.ErrClosed) {
+			return nil, ErrTenderClosed
 		}
-		return bid, nil
-	} else {
-		bid, err := s.bidRepo.PutStatus(ctx, bidId, "Canceled")
-		if err != nil {
+		if errors.Is(err, repoerrs.ErrAlreadyDecided) {
+			return nil, ErrBidAlreadyDecided
+		}
+		if errors.Is(err, repoerrs.ErrAlreadyExists) {
+			return nil, ErrDecisionAlreadySubmitted
+		}
+		return nil, ErrCannotSubmitDecision
+	}
+	return bid, nil
+}
+
+func (s *BidService) SubmitFeedback(ctx context.Context, input SubmitFeedbackInput) (*entity.Bid, error) {
+	_, bid, err := s.checkDecidable(ctx, input.TenderId, input.BidId)
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.bidRepo.CreateFeedback(ctx, input.BidId, input.EmployeeId, input.Feedback)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return nil, ErrCannotSubmitFeedback
+		}
+		return nil, ErrCannotSaveFeedback
+	}
+	return bid, nil
+}
+
+// GetReviews lists the feedback left on the author's bids. Only the author of
+// a bid on the tender can be reviewed through it.
+func (s *BidService) GetReviews(ctx context.Context, input GetReviewsInput) ([]GetReviewsOutput, error) {
+	hasBid, err := s.bidRepo.HasAuthorBid(ctx, input.TenderId, input.AuthorId)
+	if err != nil {
+		return nil, ErrCannotGetReviews
+	}
+	if !hasBid {
+		return nil, ErrAuthorBidNotFound
+	}
+	feedback, err := s.bidRepo.GetAuthorFeedback(ctx, input.AuthorId, input.Limit, input.Offset)
+	if err != nil {
+		return nil, ErrCannotGetReviews
+	}
+	output := make([]GetReviewsOutput, len(feedback))
+	for i, f := range feedback {
+		output[i] = GetReviewsOutput{
+			Id:          f.Id,
+			Description: f.Description,
+			CreatedAt:   f.CreatedAt.Format(formating.TimeFormat),
+		}
+	}
+	return output, nil
+}
+
+func (s *BidService) GetVersions(ctx context.Context, id uuid.UUID) ([]BidVersionOutput, error) {
+	bids, err := s.bidRepo.GetVersions(ctx, id)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
 			return nil, ErrBidNotFound
 		}
-		return bid, nil
+		return nil, ErrCannotGetVersions
 	}
+	output := make([]BidVersionOutput, len(bids))
+	for i, bid := range bids {
+		output[i] = BidVersionOutput{
+			Version:      bid.Version,
+			Name:         bid.Name,
+			Description:  bid.Description,
+			Status:       bid.Status,
+			AuthorType:   bid.AuthorType,
+			AuthorId:     bid.AuthorId,
+			EditedBy:     bid.EditedBy,
+			ChangeReason: bid.ChangeReason,
+			UpdatedAt:    bid.UpdatedAt.Format(formating.TimeFormat),
+		}
+	}
+	return output, nil
+}
+
+// DiffVersions compares the name and description of two versions.
+func (s *BidService) DiffVersions(ctx context.Context, input DiffVersionsInput) (*DiffVersionsOutput, error) {
+	from, err := s.bidRepo.GetVersion(ctx, input.Id, input.From)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrVersionNotFound) {
+			return nil, ErrVersionNotFound
+		}
+		return nil, ErrCannotGetVersions
+	}
+	to, err := s.bidRepo.GetVersion(ctx, input.Id, input.To)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrVersionNotFound) {
+			return nil, ErrVersionNotFound
+		}
+		return nil, ErrCannotGetVersions
+	}
+	changes := make([]FieldChange, 0)
+	changes = appendChange(changes, "name", from.Name, to.Name)
+	changes = appendChange(changes, "description", from.Description, to.Description)
+	return &DiffVersionsOutput{
+		From:    from.Version,
+		To:      to.Version,
+		Changes: changes,
+	}, nil
 }
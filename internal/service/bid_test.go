@@ -0,0 +1,261 @@
+package service
+
+import (
+	"avito/internal/entity"
+	"avito/internal/repo"
+	"avito/internal/repo/repoerrs"
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"testing"
+)
+
+var errDB = errors.New("db is down")
+
+type fakeTenderRepo struct {
+	repo.Tender
+	tenders map[uuid.UUID]*entity.Tender
+}
+
+func (f *fakeTenderRepo) GetTenderById(_ context.Context, tenderId uuid.UUID) (*entity.Tender, error) {
+	t, ok := f.tenders[tenderId]
+	if !ok {
+		return nil, repoerrs.ErrNotFound
+	}
+	return t, nil
+}
+
+// fakeBidRepo serves bids from a map and records the quorum every decision
+// was submitted with and the feedback it stored.
+type fakeBidRepo struct {
+	repo.Bid
+	bids        map[uuid.UUID]*entity.Bid
+	decisionErr error
+	quorums     []int
+	feedbackErr error
+	feedback    []string
+}
+
+func (f *fakeBidRepo) GetBidById(_ context.Context, bidId uuid.UUID) (*entity.Bid, error) {
+	b, ok := f.bids[bidId]
+	if !ok {
+		return nil, repoerrs.ErrNotFound
+	}
+	return b, nil
+}
+
+func (f *fakeBidRepo) SubmitDecision(_ context.Context, _, bidId, _ uuid.UUID, _ string, quorum int, _ string) (*entity.Bid, error) {
+	f.quorums = append(f.quorums, quorum)
+	if f.decisionErr != nil {
+		return nil, f.decisionErr
+	}
+	return f.bids[bidId], nil
+}
+
+func (f *fakeBidRepo) CreateFeedback(_ context.Context, bidId, employeeId uuid.UUID, description string) (*entity.BidFeedback, error) {
+	if f.feedbackErr != nil {
+		return nil, f.feedbackErr
+	}
+	f.feedback = append(f.feedback, description)
+	return &entity.BidFeedback{Id: uuid.New(), BidId: bidId, EmployeeId: employeeId, Description: description}, nil
+}
+
+type fakeEmployeeRepo struct {
+	repo.Employee
+	responsibles int
+	err          error
+}
+
+func (f *fakeEmployeeRepo) CountOrganizationResponsibles(_ context.Context, _ uuid.UUID) (int, error) {
+	return f.responsibles, f.err
+}
+
+// bidFixture is a published tender with one published bid on it.
+type bidFixture struct {
+	tender *entity.Tender
+	bid    *entity.Bid
+}
+
+func newBidFixture() bidFixture {
+	tender := &entity.Tender{Id: uuid.New(), Status: "Published", OrganizationId: uuid.New()}
+	return bidFixture{
+		tender: tender,
+		bid:    &entity.Bid{Id: uuid.New(), TenderId: tender.Id, Status: "Published"},
+	}
+}
+
+func (f bidFixture) service(bids *fakeBidRepo, employees *fakeEmployeeRepo) *BidService {
+	bids.bids = map[uuid.UUID]*entity.Bid{f.bid.Id: f.bid}
+	tenders := &fakeTenderRepo{tenders: map[uuid.UUID]*entity.Tender{f.tender.Id: f.tender}}
+	return NewBidService(bids, tenders, employees)
+}
+
+func TestSubmitDecisionQuorum(t *testing.T) {
+	tests := []struct {
+		name         string
+		responsibles int
+		quorum       int
+	}{
+		{name: "single responsible", responsibles: 1, quorum: 1},
+		{name: "fewer responsibles than the quorum", responsibles: 2, quorum: 2},
+		{name: "as many responsibles as the quorum", responsibles: decisionQuorum, quorum: decisionQuorum},
+		{name: "more responsibles than the quorum", responsibles: 10, quorum: decisionQuorum},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newBidFixture()
+			bids := &fakeBidRepo{}
+			s := f.service(bids, &fakeEmployeeRepo{responsibles: tt.responsibles})
+
+			_, err := s.SubmitDecision(context.Background(), SubmitDecisionInput{
+				TenderId:   f.tender.Id,
+				BidId:      f.bid.Id,
+				EmployeeId: uuid.New(),
+				Decision:   "Approved",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(bids.quorums) != 1 || bids.quorums[0] != tt.quorum {
+				t.Fatalf("submitted with quorums %v, want [%d]", bids.quorums, tt.quorum)
+			}
+		})
+	}
+}
+
+func TestSubmitDecisionErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		prepare     func(f bidFixture)
+		employees   *fakeEmployeeRepo
+		decisionErr error
+		want        error
+	}{
+		{
+			name:    "tender not published",
+			prepare: func(f bidFixture) { f.tender.Status = "Created" },
+			want:    ErrInvalidTransition,
+		},
+		{
+			name:    "bid not published",
+			prepare: func(f bidFixture) { f.bid.Status = "Created" },
+			want:    ErrInvalidTransition,
+		},
+		{
+			name:      "responsibles lookup fails",
+			employees: &fakeEmployeeRepo{err: errDB},
+			want:      ErrCannotSubmitDecision,
+		},
+		{
+			name:        "tender closed meanwhile",
+			decisionErr: repoerrs.ErrClosed,
+			want:        ErrTenderClosed,
+		},
+		{
+			name:        "bid already decided",
+			decisionErr: repoerrs.ErrAlreadyDecided,
+			want:        ErrBidAlreadyDecided,
+		},
+		{
+			name:        "decision already submitted",
+			decisionErr: repoerrs.ErrAlreadyExists,
+			want:        ErrDecisionAlreadySubmitted,
+		},
+		{
+			name:        "decision insert fails",
+			decisionErr: errDB,
+			want:        ErrCannotSubmitDecision,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newBidFixture()
+			if tt.prepare != nil {
+				tt.prepare(f)
+			}
+			employees := tt.employees
+			if employees == nil {
+				employees = &fakeEmployeeRepo{responsibles: 1}
+			}
+			bids := &fakeBidRepo{decisionErr: tt.decisionErr}
+			s := f.service(bids, employees)
+
+			_, err := s.SubmitDecision(context.Background(), SubmitDecisionInput{
+				TenderId:   f.tender.Id,
+				BidId:      f.bid.Id,
+				EmployeeId: uuid.New(),
+				Decision:   "Approved",
+			})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubmitFeedback(t *testing.T) {
+	tests := []struct {
+		name        string
+		prepare     func(f bidFixture)
+		feedbackErr error
+		want        error
+	}{
+		{name: "published bid on a published tender"},
+		{
+			name:    "tender closed",
+			prepare: func(f bidFixture) { f.tender.Status = "Closed" },
+			want:    ErrInvalidTransition,
+		},
+		{
+			name:    "bid not published",
+			prepare: func(f bidFixture) { f.bid.Status = "Created" },
+			want:    ErrInvalidTransition,
+		},
+		{
+			name:    "bid canceled",
+			prepare: func(f bidFixture) { f.bid.Status = "Canceled" },
+			want:    ErrInvalidTransition,
+		},
+		{
+			name:    "bid on another tender",
+			prepare: func(f bidFixture) { f.bid.TenderId = uuid.New() },
+			want:    ErrBidNotFound,
+		},
+		{
+			name:        "reviewer no longer exists",
+			feedbackErr: repoerrs.ErrNotFound,
+			want:        ErrCannotSubmitFeedback,
+		},
+		{
+			name:        "feedback insert fails",
+			feedbackErr: errDB,
+			want:        ErrCannotSaveFeedback,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newBidFixture()
+			if tt.prepare != nil {
+				tt.prepare(f)
+			}
+			bids := &fakeBidRepo{feedbackErr: tt.feedbackErr}
+			s := f.service(bids, &fakeEmployeeRepo{})
+
+			_, err := s.SubmitFeedback(context.Background(), SubmitFeedbackInput{
+				TenderId:   f.tender.Id,
+				BidId:      f.bid.Id,
+				EmployeeId: uuid.New(),
+				Feedback:   "late delivery",
+			})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+			if tt.want == nil && len(bids.feedback) != 1 {
+				t.Fatalf("stored feedback %v, want one", bids.feedback)
+			}
+			if tt.want != nil && tt.feedbackErr == nil && len(bids.feedback) != 0 {
+				t.Fatalf("feedback was stored: %v", bids.feedback)
+			}
+		})
+	}
+}
@@ -0,0 +1,13 @@
+package service
+
+// appendChange records the field when its value differs between two versions.
+func appendChange(changes []FieldChange, field, from, to string) []FieldChange {
+	if from == to {
+		return changes
+	}
+	return append(changes, FieldChange{
+		Field: field,
+		From:  from,
+		To:    to,
+	})
+}
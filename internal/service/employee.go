@@ -1,9 +1,12 @@
 package service
 
 import (
+	"avito/internal/controllers/http/formating"
 	"avito/internal/entity"
 	"avito/internal/repo"
+	"avito/internal/repo/repoerrs"
 	"context"
+	"errors"
 	"github.com/google/uuid"
 )
 
@@ -31,10 +34,91 @@ func (s *EmployeeService) GetEmployeeById(ctx context.Context, id uuid.UUID) (*e
 	return e, nil
 }
 
-func (s *EmployeeService) GetEmployeeOrgIdById(ctx context.Context, employeeId uuid.UUID) (uuid.UUID, error) {
-	id, err := s.employeeRepo.GetEmployeeOrgIdById(ctx, employeeId)
+// GetEmployeeOrgIdsById returns every organization the employee is responsible
+// for, failing with ErrOrganisationResponsibleNotFound when there is none.
+func (s *EmployeeService) GetEmployeeOrgIdsById(ctx context.Context, employeeId uuid.UUID) ([]uuid.UUID, error) {
+	ids, err := s.employeeRepo.GetEmployeeOrgIdsById(ctx, employeeId)
+	if err != nil || len(ids) == 0 {
+		return nil, ErrOrganisationResponsibleNotFound
+	}
+	return ids, nil
+}
+
+func (s *EmployeeService) CreateEmployee(ctx context.Context, input CreateEmployeeInput) (*EmployeeOutput, error) {
+	e, err := s.employeeRepo.CreateEmployee(ctx, input.Username, input.FirstName, input.LastName)
 	if err != nil {
-		return uuid.New(), ErrOrganisationResponsibleNotFound
+		if errors.Is(err, repoerrs.ErrAlreadyExists) {
+			return nil, ErrEmployeeAlreadyExists
+		}
+		return nil, ErrCannotCreateEmployee
 	}
-	return id, nil
+	output := toEmployeeOutput(e)
+	return &output, nil
+}
+
+// GetEmployeeByUsername looks up any employee, including deactivated ones.
+func (s *EmployeeService) GetEmployeeByUsername(ctx context.Context, username string) (*EmployeeOutput, error) {
+	e, err := s.employeeRepo.GetEmployeeByUsername(ctx, username)
+	if err != nil {
+		return nil, ErrEmployeeDoesNotExist
+	}
+	output := toEmployeeOutput(e)
+	return &output, nil
+}
+
+func (s *EmployeeService) EditEmployee(ctx context.Context, input EditEmployeeInput) (*EmployeeOutput, error) {
+	e, err := s.employeeRepo.UpdateEmployeeNames(ctx, input.Id, input.FirstName, input.LastName)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return nil, ErrEmployeeDoesNotExist
+		}
+		return nil, ErrCannotEditEmployee
+	}
+	output := toEmployeeOutput(e)
+	return &output, nil
+}
+
+func (s *EmployeeService) DeactivateEmployee(ctx context.Context, id uuid.UUID) (*EmployeeOutput, error) {
+	e, err := s.employeeRepo.DeactivateEmployee(ctx, id)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return nil, ErrEmployeeDoesNotExist
+		}
+		return nil, ErrCannotDeactivateEmployee
+	}
+	output := toEmployeeOutput(e)
+	return &output, nil
+}
+
+// GetProfile returns the employee together with the organizations they are
+// responsible for, which are empty for employees outside any organization.
+func (s *EmployeeService) GetProfile(ctx context.Context, id uuid.UUID) (*ProfileOutput, error) {
+	e, err := s.employeeRepo.GetEmployeeById(ctx, id)
+	if err != nil {
+		return nil, ErrEmployeeDoesNotExist
+	}
+	orgIds, err := s.employeeRepo.GetEmployeeOrgIdsById(ctx, id)
+	if err != nil {
+		return nil, ErrOrganisationResponsibleNotFound
+	}
+	return &ProfileOutput{
+		EmployeeOutput:  toEmployeeOutput(e),
+		OrganizationIds: orgIds,
+	}, nil
+}
+
+func toEmployeeOutput(e *entity.Employee) EmployeeOutput {
+	output := EmployeeOutput{
+		Id:        e.Id,
+		Username:  e.Username,
+		FirstName: e.FirstName,
+		LastName:  e.LastName,
+		CreatedAt: e.CreatedAt.Format(formating.TimeFormat),
+		UpdatedAt: e.UpdatedAt.Format(formating.TimeFormat),
+	}
+	if e.DeactivatedAt != nil {
+		deactivatedAt := e.DeactivatedAt.Format(formating.TimeFormat)
+		output.DeactivatedAt = &deactivatedAt
+	}
+	return output
 }
@@ -17,4 +17,47 @@ var (
 	ErrCannotGetBids                   = fmt.Errorf("can not get bid")
 	ErrBidNotFound                     = fmt.Errorf("bid not found")
 	ErrCannotEditBid                   = fmt.Errorf("can not edit bid")
+	ErrCannotSubmitDecision            = fmt.Errorf("can not submit decision")
+	ErrDecisionAlreadySubmitted        = fmt.Errorf("decision already submitted")
+	ErrBidAlreadyDecided               = fmt.Errorf("bid already decided")
+	ErrTenderClosed                    = fmt.Errorf("tender closed")
+	ErrCannotSubmitFeedback            = fmt.Errorf("can not submit feedback")
+	ErrCannotGetReviews                = fmt.Errorf("can not get reviews")
+	ErrVersionConflict                 = fmt.Errorf("version conflict, try again")
+	ErrCannotRollback                  = fmt.Errorf("can not rollback version")
+	ErrVersionMismatch                 = fmt.Errorf("version mismatch, it was modified by someone else")
+	ErrInvalidTransition               = fmt.Errorf("invalid status transition")
+	ErrTenderNotPublished              = fmt.Errorf("tender is not published")
+	ErrOwnTender                       = fmt.Errorf("can not bid on own organization tender")
+	ErrInvalidToken                    = fmt.Errorf("invalid token")
+	ErrUnauthenticated                 = fmt.Errorf("authentication required")
+	ErrInvalidScope                    = fmt.Errorf("invalid api key scope")
+	ErrCannotCreateApiKey              = fmt.Errorf("can not create api key")
+	ErrCannotGetApiKeys                = fmt.Errorf("can not get api keys")
+	ErrCannotRevokeApiKey              = fmt.Errorf("can not revoke api key")
+	ErrApiKeyNotFound                  = fmt.Errorf("api key not found")
+	ErrInvalidApiKey                   = fmt.Errorf("invalid api key")
+	ErrInsufficientScope               = fmt.Errorf("api key scope does not allow this action")
+	ErrCannotGetVersions               = fmt.Errorf("can not get versions")
+	ErrCannotCreateOrganization        = fmt.Errorf("can not create organization")
+	ErrCannotGetOrganizations          = fmt.Errorf("can not get organizations")
+	ErrOrganizationNotFound            = fmt.Errorf("organization not found")
+	ErrCannotEditOrganization          = fmt.Errorf("can not edit organization")
+	ErrCannotDeleteOrganization        = fmt.Errorf("can not delete organization")
+	ErrCannotGetResponsibles           = fmt.Errorf("can not get responsibles")
+	ErrCannotPutResponsible            = fmt.Errorf("can not put responsible")
+	ErrCannotRemoveResponsible         = fmt.Errorf("can not remove responsible")
+	ErrResponsibleNotFound             = fmt.Errorf("responsible not found")
+	ErrLastAdmin                       = fmt.Errorf("organization must keep at least one admin")
+	ErrEmployeeAlreadyExists           = fmt.Errorf("employee already exists")
+	ErrCannotCreateEmployee            = fmt.Errorf("can not create employee")
+	ErrCannotEditEmployee              = fmt.Errorf("can not edit employee")
+	ErrCannotDeactivateEmployee        = fmt.Errorf("can not deactivate employee")
+	ErrInvalidBidOrganization          = fmt.Errorf("organizationId is required for organization bids only")
+	ErrInvalidDeadline                 = fmt.Errorf("deadlines must be in the future and decisionDeadline must not precede submissionDeadline")
+	ErrSubmissionClosed                = fmt.Errorf("tender submission deadline has passed")
+	ErrCannotCloseExpiredTenders       = fmt.Errorf("can not close expired tenders")
+	ErrCannotCheckTender               = fmt.Errorf("can not check tender")
+	ErrCannotSaveFeedback              = fmt.Errorf("can not save feedback")
+	ErrAuthorBidNotFound               = fmt.Errorf("author has no bid on the tender")
 )
@@ -0,0 +1,26 @@
+package service
+
+import "slices"
+
+// tenderTransitions lists the statuses a tender may move to from each status.
+var tenderTransitions = map[string][]string{
+	"Created":   {"Published", "Closed"},
+	"Published": {"Closed"},
+	"Closed":    {},
+}
+
+// bidTransitions lists the statuses a bid may move to from each status.
+var bidTransitions = map[string][]string{
+	"Created":   {"Published", "Canceled"},
+	"Published": {"Canceled"},
+	"Canceled":  {},
+}
+
+func canTransit(transitions map[string][]string, from, to string) bool {
+	return slices.Contains(transitions[from], to)
+}
+
+// isFinal reports whether no transitions lead out of the status.
+func isFinal(transitions map[string][]string, status string) bool {
+	return len(transitions[status]) == 0
+}
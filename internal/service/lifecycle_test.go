@@ -0,0 +1,45 @@
+package service
+
+import "testing"
+
+func TestTransitions(t *testing.T) {
+	tests := []struct {
+		name        string
+		transitions map[string][]string
+		from, to    string
+		want        bool
+	}{
+		{name: "tender published", transitions: tenderTransitions, from: "Created", to: "Published", want: true},
+		{name: "draft tender closed", transitions: tenderTransitions, from: "Created", to: "Closed", want: true},
+		{name: "published tender closed", transitions: tenderTransitions, from: "Published", to: "Closed", want: true},
+		{name: "published tender back to draft", transitions: tenderTransitions, from: "Published", to: "Created"},
+		{name: "closed tender reopened", transitions: tenderTransitions, from: "Closed", to: "Published"},
+		{name: "tender to the same status", transitions: tenderTransitions, from: "Published", to: "Published"},
+		{name: "bid published", transitions: bidTransitions, from: "Created", to: "Published", want: true},
+		{name: "draft bid canceled", transitions: bidTransitions, from: "Created", to: "Canceled", want: true},
+		{name: "published bid canceled", transitions: bidTransitions, from: "Published", to: "Canceled", want: true},
+		{name: "published bid back to draft", transitions: bidTransitions, from: "Published", to: "Created"},
+		{name: "canceled bid republished", transitions: bidTransitions, from: "Canceled", to: "Published"},
+		{name: "unknown status", transitions: bidTransitions, from: "Approved", to: "Canceled"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := canTransit(tt.transitions, tt.from, tt.to); got != tt.want {
+				t.Fatalf("canTransit(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsFinal(t *testing.T) {
+	for status, want := range map[string]bool{"Created": false, "Published": false, "Closed": true} {
+		if got := isFinal(tenderTransitions, status); got != want {
+			t.Errorf("tender %s final = %v, want %v", status, got, want)
+		}
+	}
+	for status, want := range map[string]bool{"Created": false, "Published": false, "Canceled": true} {
+		if got := isFinal(bidTransitions, status); got != want {
+			t.Errorf("bid %s final = %v, want %v", status, got, want)
+		}
+	}
+}
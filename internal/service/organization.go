@@ -0,0 +1,124 @@
+package service
+
+import (
+	"avito/internal/controllers/http/formating"
+	"avito/internal/entity"
+	"avito/internal/repo"
+	"avito/internal/repo/repoerrs"
+	"context"
+	"errors"
+	"github.com/google/uuid"
+)
+
+type OrganizationService struct {
+	organizationRepo repo.Organization
+}
+
+func NewOrganizationService(organizationRepo repo.Organization) *OrganizationService {
+	return &OrganizationService{organizationRepo: organizationRepo}
+}
+
+// CreateOrganization creates the organization with its creator as the admin.
+func (s *OrganizationService) CreateOrganization(ctx context.Context, input CreateOrganizationInput) (*OrganizationOutput, error) {
+	o, err := s.organizationRepo.CreateOrganization(ctx, input.Name, input.Description, input.Type, input.CreatedBy)
+	if err != nil {
+		return nil, ErrCannotCreateOrganization
+	}
+	output := toOrganizationOutput(o)
+	return &output, nil
+}
+
+func (s *OrganizationService) GetOrganizations(ctx context.Context, limit, offset int) ([]OrganizationOutput, error) {
+	organizations, err := s.organizationRepo.GetOrganizations(ctx, limit, offset)
+	if err != nil {
+		return nil, ErrCannotGetOrganizations
+	}
+	output := make([]OrganizationOutput, len(organizations))
+	for i := range organizations {
+		output[i] = toOrganizationOutput(&organizations[i])
+	}
+	return output, nil
+}
+
+func (s *OrganizationService) GetOrganizationById(ctx context.Context, id uuid.UUID) (*OrganizationOutput, error) {
+	o, err := s.organizationRepo.GetOrganizationById(ctx, id)
+	if err != nil {
+		return nil, ErrOrganizationNotFound
+	}
+	output := toOrganizationOutput(o)
+	return &output, nil
+}
+
+func (s *OrganizationService) EditOrganization(ctx context.Context, input EditOrganizationInput) (*OrganizationOutput, error) {
+	o, err := s.organizationRepo.UpdateOrganization(ctx, input.Id, input.Name, input.Description, input.Type)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return nil, ErrOrganizationNotFound
+		}
+		return nil, ErrCannotEditOrganization
+	}
+	output := toOrganizationOutput(o)
+	return &output, nil
+}
+
+func (s *OrganizationService) DeleteOrganization(ctx context.Context, id uuid.UUID) error {
+	if err := s.organizationRepo.DeleteOrganization(ctx, id); err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return ErrOrganizationNotFound
+		}
+		return ErrCannotDeleteOrganization
+	}
+	return nil
+}
+
+func (s *OrganizationService) GetResponsibles(ctx context.Context, organizationId uuid.UUID) ([]ResponsibleOutput, error) {
+	responsibles, err := s.organizationRepo.GetResponsibles(ctx, organizationId)
+	if err != nil {
+		return nil, ErrCannotGetResponsibles
+	}
+	output := make([]ResponsibleOutput, len(responsibles))
+	for i, r := range responsibles {
+		output[i] = ResponsibleOutput{UserId: r.UserId, Role: r.Role}
+	}
+	return output, nil
+}
+
+// PutResponsible assigns the employee to the organization with the given role,
+// or changes the role of an existing responsible.
+func (s *OrganizationService) PutResponsible(ctx context.Context, input PutResponsibleInput) (*ResponsibleOutput, error) {
+	r, err := s.organizationRepo.PutResponsible(ctx, input.OrganizationId, input.UserId, input.Role)
+	if err != nil {
+		switch {
+		case errors.Is(err, repoerrs.ErrLastAdmin):
+			return nil, ErrLastAdmin
+		case errors.Is(err, repoerrs.ErrNotFound):
+			return nil, ErrEmployeeDoesNotExist
+		}
+		return nil, ErrCannotPutResponsible
+	}
+	return &ResponsibleOutput{UserId: r.UserId, Role: r.Role}, nil
+}
+
+func (s *OrganizationService) RemoveResponsible(ctx context.Context, organizationId, userId uuid.UUID) error {
+	if err := s.organizationRepo.RemoveResponsible(ctx, organizationId, userId); err != nil {
+		switch {
+		case errors.Is(err, repoerrs.ErrLastAdmin):
+			return ErrLastAdmin
+		case errors.Is(err, repoerrs.ErrNotFound):
+			return ErrResponsibleNotFound
+		}
+		return ErrCannotRemoveResponsible
+	}
+	return nil
+}
+
+func toOrganizationOutput(o *entity.Organization) OrganizationOutput {
+	return OrganizationOutput{
+		Id:          o.Id,
+		Name:        o.Name,
+		Description: o.Description,
+		Type:        o.Type,
+		CreatedAt:   o.CreatedAt.Format(formating.TimeFormat),
+		UpdatedAt:   o.UpdatedAt.Format(formating.TimeFormat),
+	}
+}
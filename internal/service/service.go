@@ -8,45 +8,71 @@ import (
 	"time"
 )
 
+// versionRetries is how many times an edit or rollback is attempted when a
+// concurrent writer creates the same version first.
+const versionRetries = 3
+
 type Services struct {
-	Tender   Tender
-	Employee Employee
-	Bid      Bid
+	Tender       Tender
+	Employee     Employee
+	Bid          Bid
+	Auth         Auth
+	ApiKey       ApiKey
+	Organization Organization
 }
 
 type ServicesDependencies struct {
-	Repos *repo.Repositories
+	Repos   *repo.Repositories
+	SignKey string
 }
 
 type TenderCreateInput struct {
-	Name            string
-	Description     string
-	ServiceType     string
-	OrganizationId  uuid.UUID
-	CreatorUsername string
+	Name               string
+	Description        string
+	ServiceType        string
+	OrganizationId     uuid.UUID
+	CreatorUsername    string
+	CreatorId          uuid.UUID
+	SubmissionDeadline *time.Time
+	DecisionDeadline   *time.Time
 }
 
 type GetMyTendersInput struct {
-	Username string
-	Limit    int
-	Offset   int
+	Username  string
+	Filter    entity.ListFilter
+	Limit     int
+	Offset    int
+	WithTotal bool
 }
 
 type GetMyTendersOutput struct {
-	Id             uuid.UUID `json:"id"`
-	Name           string    `json:"name"`
-	Description    string    `json:"description"`
-	Status         string    `json:"status"`
-	ServiceType    string    `json:"serviceType"`
-	OrganizationId uuid.UUID `json:"organizationTd"`
-	Version        int       `json:"version"`
-	CreatedAt      string    `json:"createdAt"`
+	Id                 uuid.UUID `json:"id"`
+	Name               string    `json:"name"`
+	Description        string    `json:"description"`
+	Status             string    `json:"status"`
+	ServiceType        string    `json:"serviceType"`
+	OrganizationId     uuid.UUID `json:"organizationTd"`
+	Version            int       `json:"version"`
+	CreatedAt          string    `json:"createdAt"`
+	SubmissionDeadline *string   `json:"submissionDeadline"`
+	DecisionDeadline   *string   `json:"decisionDeadline"`
+}
+
+// TendersPage is one page of a tender listing. Next is set when the page is
+// full, so the listing may continue after it; Total is only counted on request.
+type TendersPage struct {
+	Tenders []GetMyTendersOutput
+	Next    *entity.Cursor
+	Total   *int
 }
 
 type GetTendersInput struct {
 	ServiceTypes []string
+	Query        string
+	Filter       entity.ListFilter
 	Limit        int
 	Offset       int
+	WithTotal    bool
 }
 type GetStatusInput struct {
 	Username string
@@ -57,44 +83,60 @@ type PutStatusInput struct {
 	Username string
 	TenderId uuid.UUID
 	Status   string
+	EditedBy uuid.UUID
 }
 type PutStatusOutput struct {
-	Id          uuid.UUID
-	Name        string
-	Description string
-	Status      string
-	ServiceType string
-	Version     int
-	CreatedAt   time.Time
+	Id           uuid.UUID
+	Name         string
+	Description  string
+	Status       string
+	ServiceType  string
+	Version      int
+	CreatedAt    time.Time
+	EditedBy     *uuid.UUID
+	ChangeReason *string
 }
 
 type EditTenderInput struct {
-	Id          uuid.UUID
-	Name        string
-	Description string
-	ServiceType string
+	Id                 uuid.UUID
+	ExpectedVersion    int
+	Name               string
+	Description        string
+	ServiceType        string
+	EditedBy           uuid.UUID
+	Reason             string
+	SubmissionDeadline *time.Time
+	DecisionDeadline   *time.Time
 }
 type EditTenderOutput struct {
-	Id          uuid.UUID
-	Name        string
-	Description string
-	Status      string
-	ServiceType string
-	Version     int
-	CreatedAt   time.Time
+	Id                 uuid.UUID
+	Name               string
+	Description        string
+	Status             string
+	ServiceType        string
+	Version            int
+	CreatedAt          time.Time
+	EditedBy           *uuid.UUID
+	ChangeReason       *string
+	SubmissionDeadline *time.Time
+	DecisionDeadline   *time.Time
 }
 type RollbackVersionInput struct {
-	Id      uuid.UUID
-	Version int
+	Id       uuid.UUID
+	Version  int
+	EditedBy uuid.UUID
+	Reason   string
 }
 type RollbackVersionOutput struct {
-	Id          uuid.UUID
-	Name        string
-	Description string
-	Status      string
-	ServiceType string
-	Version     int
-	CreatedAt   time.Time
+	Id           uuid.UUID
+	Name         string
+	Description  string
+	Status       string
+	ServiceType  string
+	Version      int
+	CreatedAt    time.Time
+	EditedBy     *uuid.UUID
+	ChangeReason *string
 }
 type BidCreateInput struct {
 	Name        string
@@ -102,6 +144,9 @@ type BidCreateInput struct {
 	TenderId    uuid.UUID
 	AuthorType  string
 	AuthorId    uuid.UUID
+	// OrganizationId is required for "Organization" bids and must be one of
+	// the author's organizations.
+	OrganizationId *uuid.UUID
 }
 
 type GetMyBidsOutput struct {
@@ -114,10 +159,28 @@ type GetMyBidsOutput struct {
 	CreatedAt  string    `json:"createdAt"`
 }
 
+// BidsPage is one page of a bid listing, see TendersPage.
+type BidsPage struct {
+	Bids  []GetMyBidsOutput
+	Next  *entity.Cursor
+	Total *int
+}
+
 type GetMyBidsInput struct {
-	AuthorId uuid.UUID
-	Limit    int
-	Offset   int
+	AuthorId  uuid.UUID
+	Filter    entity.ListFilter
+	Limit     int
+	Offset    int
+	WithTotal bool
+}
+
+type GetBidsForTenderInput struct {
+	TenderId      uuid.UUID
+	EmployeeId    uuid.UUID
+	IsResponsible bool
+	Filter        entity.ListFilter
+	Limit         int
+	Offset        int
 }
 
 type GetBidStatusInput struct {
@@ -126,76 +189,273 @@ type GetBidStatusInput struct {
 }
 
 type PutBidStatusInput struct {
-	BidId  uuid.UUID
-	Status string
+	BidId    uuid.UUID
+	Status   string
+	EditedBy uuid.UUID
 }
 
 type PutBidStatusOutput struct {
-	Id         uuid.UUID `json:"id"`
-	Name       string    `json:"name"`
-	Status     string    `json:"status"`
-	AuthorType string    `json:"authorType"`
-	AuthorId   uuid.UUID `json:"authorTd"`
-	Version    int       `json:"version"`
-	CreatedAt  time.Time `json:"createdAt"`
+	Id           uuid.UUID  `json:"id"`
+	Name         string     `json:"name"`
+	Status       string     `json:"status"`
+	AuthorType   string     `json:"authorType"`
+	AuthorId     uuid.UUID  `json:"authorTd"`
+	Version      int        `json:"version"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	EditedBy     *uuid.UUID `json:"editedBy"`
+	ChangeReason *string    `json:"changeReason"`
 }
 
 type EditBidInput struct {
-	Id          uuid.UUID
-	Name        string
-	Description string
+	Id              uuid.UUID
+	ExpectedVersion int
+	Name            string
+	Description     string
+	EditedBy        uuid.UUID
+	Reason          string
 }
 type EditBidOutput struct {
-	Id         uuid.UUID `json:"id"`
-	Name       string    `json:"name"`
-	Status     string    `json:"status"`
-	AuthorType string    `json:"authorType"`
-	AuthorId   uuid.UUID `json:"authorTd"`
-	Version    int       `json:"version"`
-	CreatedAt  time.Time `json:"createdAt"`
+	Id           uuid.UUID  `json:"id"`
+	Name         string     `json:"name"`
+	Status       string     `json:"status"`
+	AuthorType   string     `json:"authorType"`
+	AuthorId     uuid.UUID  `json:"authorTd"`
+	Version      int        `json:"version"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	EditedBy     *uuid.UUID `json:"editedBy"`
+	ChangeReason *string    `json:"changeReason"`
 }
 
 type RollbackBidVersionOutput struct {
-	Id         uuid.UUID `json:"id"`
-	Name       string    `json:"name"`
-	Status     string    `json:"status"`
-	AuthorType string    `json:"authorType"`
-	AuthorId   uuid.UUID `json:"authorTd"`
-	Version    int       `json:"version"`
-	CreatedAt  time.Time `json:"createdAt"`
+	Id           uuid.UUID  `json:"id"`
+	Name         string     `json:"name"`
+	Status       string     `json:"status"`
+	AuthorType   string     `json:"authorType"`
+	AuthorId     uuid.UUID  `json:"authorTd"`
+	Version      int        `json:"version"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	EditedBy     *uuid.UUID `json:"editedBy"`
+	ChangeReason *string    `json:"changeReason"`
+}
+
+type SubmitDecisionInput struct {
+	TenderId   uuid.UUID
+	BidId      uuid.UUID
+	EmployeeId uuid.UUID
+	Decision   string
+}
+
+type SubmitFeedbackInput struct {
+	TenderId   uuid.UUID
+	BidId      uuid.UUID
+	EmployeeId uuid.UUID
+	Feedback   string
+}
+
+type GetReviewsInput struct {
+	TenderId uuid.UUID
+	AuthorId uuid.UUID
+	Limit    int
+	Offset   int
+}
+
+type GetReviewsOutput struct {
+	Id          uuid.UUID `json:"id"`
+	Description string    `json:"description"`
+	CreatedAt   string    `json:"createdAt"`
+}
+
+type CreateApiKeyInput struct {
+	OrganizationId uuid.UUID
+	CreatedBy      uuid.UUID
+	Scopes         []string
+}
+
+type ApiKeyOutput struct {
+	Id             uuid.UUID `json:"id"`
+	OrganizationId uuid.UUID `json:"organizationId"`
+	Scopes         []string  `json:"scopes"`
+	CreatedAt      string    `json:"createdAt"`
+	RevokedAt      *string   `json:"revokedAt,omitempty"`
+}
+
+type CreateApiKeyOutput struct {
+	ApiKeyOutput
+	Key string `json:"key"`
+}
+
+type CreateEmployeeInput struct {
+	Username  string
+	FirstName *string
+	LastName  *string
+}
+
+type EditEmployeeInput struct {
+	Id        uuid.UUID
+	FirstName *string
+	LastName  *string
+}
+
+type EmployeeOutput struct {
+	Id            uuid.UUID `json:"id"`
+	Username      string    `json:"username"`
+	FirstName     *string   `json:"firstName"`
+	LastName      *string   `json:"lastName"`
+	CreatedAt     string    `json:"createdAt"`
+	UpdatedAt     string    `json:"updatedAt"`
+	DeactivatedAt *string   `json:"deactivatedAt,omitempty"`
+}
+
+type ProfileOutput struct {
+	EmployeeOutput
+	OrganizationIds []uuid.UUID `json:"organizationIds"`
+}
+
+type CreateOrganizationInput struct {
+	Name        string
+	Description *string
+	Type        *string
+	CreatedBy   uuid.UUID
+}
+
+type EditOrganizationInput struct {
+	Id          uuid.UUID
+	Name        *string
+	Description *string
+	Type        *string
+}
+
+type OrganizationOutput struct {
+	Id          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Description *string   `json:"description"`
+	Type        *string   `json:"type"`
+	CreatedAt   string    `json:"createdAt"`
+	UpdatedAt   string    `json:"updatedAt"`
+}
+
+type PutResponsibleInput struct {
+	OrganizationId uuid.UUID
+	UserId         uuid.UUID
+	Role           string
+}
+
+type ResponsibleOutput struct {
+	UserId uuid.UUID `json:"userId"`
+	Role   string    `json:"role"`
+}
+
+type TenderVersionOutput struct {
+	Version         int        `json:"version"`
+	Name            string     `json:"name"`
+	Description     string     `json:"description"`
+	Status          string     `json:"status"`
+	ServiceType     string     `json:"serviceType"`
+	CreatorUsername string     `json:"creatorUsername"`
+	EditedBy        *uuid.UUID `json:"editedBy"`
+	ChangeReason    *string    `json:"changeReason"`
+	UpdatedAt       string     `json:"updatedAt"`
+}
+
+type BidVersionOutput struct {
+	Version      int        `json:"version"`
+	Name         string     `json:"name"`
+	Description  string     `json:"description"`
+	Status       string     `json:"status"`
+	AuthorType   string     `json:"authorType"`
+	AuthorId     uuid.UUID  `json:"authorId"`
+	EditedBy     *uuid.UUID `json:"editedBy"`
+	ChangeReason *string    `json:"changeReason"`
+	UpdatedAt    string     `json:"updatedAt"`
+}
+
+type DiffVersionsInput struct {
+	Id   uuid.UUID
+	From int
+	To   int
+}
+
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+type DiffVersionsOutput struct {
+	From    int           `json:"from"`
+	To      int           `json:"to"`
+	Changes []FieldChange `json:"changes"`
 }
 
 type Tender interface {
 	CreateTender(ctx context.Context, input TenderCreateInput) (*entity.Tender, error)
-	GetMyTenders(ctx context.Context, input GetMyTendersInput) ([]GetMyTendersOutput, error)
-	GetTenders(ctx context.Context, input GetTendersInput) ([]GetMyTendersOutput, error)
+	GetMyTenders(ctx context.Context, input GetMyTendersInput) (*TendersPage, error)
+	GetTenders(ctx context.Context, input GetTendersInput) (*TendersPage, error)
 	GetStatus(ctx context.Context, input GetStatusInput) (string, error)
 	PutStatus(ctx context.Context, input PutStatusInput) (*PutStatusOutput, error)
 	EditTender(ctx context.Context, input EditTenderInput) (*EditTenderOutput, error)
 	RollbackVersion(ctx context.Context, input RollbackVersionInput) (*RollbackVersionOutput, error)
 	GetTenderById(ctx context.Context, id uuid.UUID) (*entity.Tender, error)
+	GetVersions(ctx context.Context, id uuid.UUID) ([]TenderVersionOutput, error)
+	DiffVersions(ctx context.Context, input DiffVersionsInput) (*DiffVersionsOutput, error)
+	CloseExpiredTenders(ctx context.Context) (int, error)
 }
 type Bid interface {
 	CreateBid(ctx context.Context, input BidCreateInput) (*entity.Bid, error)
-	GetMyBids(ctx context.Context, input GetMyBidsInput) ([]GetMyBidsOutput, error)
+	GetMyBids(ctx context.Context, input GetMyBidsInput) (*BidsPage, error)
+	GetBidsForTender(ctx context.Context, input GetBidsForTenderInput) ([]GetMyBidsOutput, error)
 	GetStatus(ctx context.Context, input GetBidStatusInput) (string, error)
 	GetBidById(ctx context.Context, id uuid.UUID) (*entity.Bid, error)
 	PutStatus(ctx context.Context, input PutBidStatusInput) (*PutBidStatusOutput, error)
 	EditBid(ctx context.Context, input EditBidInput) (*EditBidOutput, error)
 	RollbackVersion(ctx context.Context, input RollbackVersionInput) (*RollbackBidVersionOutput, error)
-	SubmitDecision(ctx context.Context, tenderId, bidId uuid.UUID, decision string) (*entity.Bid, error)
+	SubmitDecision(ctx context.Context, input SubmitDecisionInput) (*entity.Bid, error)
+	SubmitFeedback(ctx context.Context, input SubmitFeedbackInput) (*entity.Bid, error)
+	GetReviews(ctx context.Context, input GetReviewsInput) ([]GetReviewsOutput, error)
+	GetVersions(ctx context.Context, id uuid.UUID) ([]BidVersionOutput, error)
+	DiffVersions(ctx context.Context, input DiffVersionsInput) (*DiffVersionsOutput, error)
+}
+
+type Auth interface {
+	ParseToken(token string) (uuid.UUID, error)
+}
+
+type ApiKey interface {
+	CreateApiKey(ctx context.Context, input CreateApiKeyInput) (*CreateApiKeyOutput, error)
+	GetApiKeys(ctx context.Context, organizationId uuid.UUID) ([]ApiKeyOutput, error)
+	RevokeApiKey(ctx context.Context, organizationId, keyId uuid.UUID) (*ApiKeyOutput, error)
+	Authenticate(ctx context.Context, key string) (*entity.ApiKey, error)
 }
 
 type Employee interface {
 	GetEmployeeIdByUsername(ctx context.Context, username string) (uuid.UUID, error)
 	GetEmployeeById(ctx context.Context, id uuid.UUID) (*entity.Employee, error)
-	GetEmployeeOrgIdById(ctx context.Context, employeeId uuid.UUID) (uuid.UUID, error)
+	GetEmployeeOrgIdsById(ctx context.Context, employeeId uuid.UUID) ([]uuid.UUID, error)
+	CreateEmployee(ctx context.Context, input CreateEmployeeInput) (*EmployeeOutput, error)
+	GetEmployeeByUsername(ctx context.Context, username string) (*EmployeeOutput, error)
+	EditEmployee(ctx context.Context, input EditEmployeeInput) (*EmployeeOutput, error)
+	DeactivateEmployee(ctx context.Context, id uuid.UUID) (*EmployeeOutput, error)
+	GetProfile(ctx context.Context, id uuid.UUID) (*ProfileOutput, error)
+}
+
+type Organization interface {
+	CreateOrganization(ctx context.Context, input CreateOrganizationInput) (*OrganizationOutput, error)
+	GetOrganizations(ctx context.Context, limit, offset int) ([]OrganizationOutput, error)
+	GetOrganizationById(ctx context.Context, id uuid.UUID) (*OrganizationOutput, error)
+	EditOrganization(ctx context.Context, input EditOrganizationInput) (*OrganizationOutput, error)
+	DeleteOrganization(ctx context.Context, id uuid.UUID) error
+	GetResponsibles(ctx context.Context, organizationId uuid.UUID) ([]ResponsibleOutput, error)
+	PutResponsible(ctx context.Context, input PutResponsibleInput) (*ResponsibleOutput, error)
+	RemoveResponsible(ctx context.Context, organizationId, userId uuid.UUID) error
 }
 
 func NewServices(deps ServicesDependencies) *Services {
 	return &Services{
-		Tender:   NewTenderService(deps.Repos.Tender),
-		Employee: NewEmployeeService(deps.Repos.Employee),
-		Bid:      NewBidService(deps.Repos.Bid, deps.Repos.Tender),
+		Tender:       NewTenderService(deps.Repos.Tender, deps.Repos.Employee),
+		Employee:     NewEmployeeService(deps.Repos.Employee),
+		Bid:          NewBidService(deps.Repos.Bid, deps.Repos.Tender, deps.Repos.Employee),
+		Auth:         NewAuthService(deps.SignKey),
+		ApiKey:       NewApiKeyService(deps.Repos.ApiKey),
+		Organization: NewOrganizationService(deps.Repos.Organization),
 	}
 }
@@ -8,17 +8,41 @@ import (
 	"context"
 	"errors"
 	"github.com/google/uuid"
+	"slices"
+	"time"
+)
+
+const (
+	// expiredTendersBatch bounds the tenders closed in one transaction.
+	expiredTendersBatch = 100
+	expiredReason       = "deadline passed"
 )
 
 type TenderService struct {
-	tenderRepo repo.Tender
+	tenderRepo   repo.Tender
+	employeeRepo repo.Employee
 }
 
-func NewTenderService(tenderRepo repo.Tender) *TenderService {
-	return &TenderService{tenderRepo: tenderRepo}
+func NewTenderService(tenderRepo repo.Tender, employeeRepo repo.Employee) *TenderService {
+	return &TenderService{
+		tenderRepo:   tenderRepo,
+		employeeRepo: employeeRepo,
+	}
 }
 
+// CreateTender creates the tender for input.OrganizationId, which the creator
+// must be responsible for; otherwise it fails with ErrPermissionDenied.
 func (s *TenderService) CreateTender(ctx context.Context, input TenderCreateInput) (*entity.Tender, error) {
+	if err := validateDeadlines(input.SubmissionDeadline, input.DecisionDeadline, time.Now()); err != nil {
+		return nil, err
+	}
+	creatorOrgs, err := s.employeeRepo.GetEmployeeOrgIdsById(ctx, input.CreatorId)
+	if err != nil {
+		return nil, ErrCannotCreateTender
+	}
+	if !slices.Contains(creatorOrgs, input.OrganizationId) {
+		return nil, ErrPermissionDenied
+	}
 	tender, err := s.tenderRepo.CreateTender(
 		ctx,
 		input.Name,
@@ -26,6 +50,8 @@ func (s *TenderService) CreateTender(ctx context.Context, input TenderCreateInpu
 		input.ServiceType,
 		input.OrganizationId,
 		input.CreatorUsername,
+		utcTime(input.SubmissionDeadline),
+		utcTime(input.DecisionDeadline),
 	)
 	if err != nil {
 		return nil, ErrCannotCreateTender
@@ -33,10 +59,11 @@ func (s *TenderService) CreateTender(ctx context.Context, input TenderCreateInpu
 	return tender, nil
 }
 
-func (s *TenderService) GetMyTenders(ctx context.Context, input GetMyTendersInput) ([]GetMyTendersOutput, error) {
+func (s *TenderService) GetMyTenders(ctx context.Context, input GetMyTendersInput) (*TendersPage, error) {
 	tenders, err := s.tenderRepo.GetMyTenders(
 		ctx,
 		input.Username,
+		input.Filter,
 		input.Limit,
 		input.Offset,
 	)
@@ -46,23 +73,38 @@ func (s *TenderService) GetMyTenders(ctx context.Context, input GetMyTendersInpu
 	output := make([]GetMyTendersOutput, len(tenders))
 	for i, tender := range tenders {
 		output[i] = GetMyTendersOutput{
-			Id:             tender.Id,
-			Name:           tender.Name,
-			Description:    tender.Description,
-			Status:         tender.Status,
-			ServiceType:    tender.Type,
-			OrganizationId: tender.OrganizationId,
-			Version:        tender.Version,
-			CreatedAt:      tender.CreatedAt.Format(formating.TimeFormat),
+			Id:                 tender.Id,
+			Name:               tender.Name,
+			Description:        tender.Description,
+			Status:             tender.Status,
+			ServiceType:        tender.Type,
+			OrganizationId:     tender.OrganizationId,
+			Version:            tender.Version,
+			CreatedAt:          tender.CreatedAt.Format(formating.TimeFormat),
+			SubmissionDeadline: formating.OptionalTime(tender.SubmissionDeadline),
+			DecisionDeadline:   formating.OptionalTime(tender.DecisionDeadline),
 		}
 	}
-	return output, nil
+	page := &TendersPage{
+		Tenders: output,
+		Next:    nextTenderCursor(tenders, input.Filter.Sort, input.Limit),
+	}
+	if input.WithTotal {
+		total, err := s.tenderRepo.CountMyTenders(ctx, input.Username, input.Filter)
+		if err != nil {
+			return nil, ErrCannotGetTender
+		}
+		page.Total = &total
+	}
+	return page, nil
 }
 
-func (s *TenderService) GetTenders(ctx context.Context, input GetTendersInput) ([]GetMyTendersOutput, error) {
+func (s *TenderService) GetTenders(ctx context.Context, input GetTendersInput) (*TendersPage, error) {
 	tenders, err := s.tenderRepo.GetTenders(
 		ctx,
 		input.ServiceTypes,
+		input.Query,
+		input.Filter,
 		input.Limit,
 		input.Offset,
 	)
@@ -72,17 +114,45 @@ func (s *TenderService) GetTenders(ctx context.Context, input GetTendersInput) (
 	output := make([]GetMyTendersOutput, len(tenders))
 	for i, tender := range tenders {
 		output[i] = GetMyTendersOutput{
-			Id:             tender.Id,
-			Name:           tender.Name,
-			Description:    tender.Description,
-			Status:         tender.Status,
-			ServiceType:    tender.Type,
-			OrganizationId: tender.OrganizationId,
-			Version:        tender.Version,
-			CreatedAt:      tender.CreatedAt.Format(formating.TimeFormat),
+			Id:                 tender.Id,
+			Name:               tender.Name,
+			Description:        tender.Description,
+			Status:             tender.Status,
+			ServiceType:        tender.Type,
+			OrganizationId:     tender.OrganizationId,
+			Version:            tender.Version,
+			CreatedAt:          tender.CreatedAt.Format(formating.TimeFormat),
+			SubmissionDeadline: formating.OptionalTime(tender.SubmissionDeadline),
+			DecisionDeadline:   formating.OptionalTime(tender.DecisionDeadline),
 		}
 	}
-	return output, nil
+	page := &TendersPage{
+		Tenders: output,
+		Next:    nextTenderCursor(tenders, input.Filter.Sort, input.Limit),
+	}
+	if input.WithTotal {
+		total, err := s.tenderRepo.CountTenders(ctx, input.ServiceTypes, input.Query, input.Filter)
+		if err != nil {
+			return nil, ErrCannotGetTender
+		}
+		page.Total = &total
+	}
+	return page, nil
+}
+
+// nextTenderCursor points after the last tender of a full page. Relevance
+// ordering can not be continued by a cursor.
+func nextTenderCursor(tenders []entity.Tender, sort string, limit int) *entity.Cursor {
+	if limit == 0 || len(tenders) < limit || sort == entity.SortByRelevance {
+		return nil
+	}
+	last := tenders[len(tenders)-1]
+	return &entity.Cursor{
+		Sort:      sort,
+		Name:      last.Name,
+		CreatedAt: last.CreatedAt,
+		Id:        last.Id,
+	}
 }
 
 func (s *TenderService) GetStatus(ctx context.Context, input GetStatusInput) (string, error) {
@@ -93,63 +163,123 @@ func (s *TenderService) GetStatus(ctx context.Context, input GetStatusInput) (st
 		}
 		return "", ErrCannotGetStatus
 	}
-	if tender.CreatorUsername != input.Username {
-		return "", ErrPermissionDenied
-	}
 	return tender.Status, nil
 }
 
+// PutStatus validates the transition against the current status and retries
+// from a fresh read when the status changed before the write locked the tender.
 func (s *TenderService) PutStatus(ctx context.Context, input PutStatusInput) (*PutStatusOutput, error) {
-	tender, err := s.tenderRepo.GetTenderById(ctx, input.TenderId)
-	if err != nil {
-		if errors.Is(err, repoerrs.ErrNotFound) {
-			return nil, ErrTenderNotFound
+	var tender *entity.Tender
+	var err error
+	for attempt := 0; attempt < versionRetries; attempt++ {
+		tender, err = s.tenderRepo.GetTenderById(ctx, input.TenderId)
+		if err != nil {
+			break
+		}
+		if !canTransit(tenderTransitions, tender.Status, input.Status) {
+			return nil, ErrInvalidTransition
+		}
+		tender, err = s.tenderRepo.PutStatus(ctx, input.TenderId, tender.Status, input.Status, input.EditedBy)
+		if !errors.Is(err, repoerrs.ErrConflict) && !errors.Is(err, repoerrs.ErrStatusMismatch) {
+			break
 		}
-		return nil, ErrCannotPutStatus
-	}
-	if tender.CreatorUsername != input.Username {
-		return nil, ErrPermissionDenied
 	}
-	tender, err = s.tenderRepo.PutStatus(ctx, input.TenderId, input.Status)
 	if err != nil {
 		if errors.Is(err, repoerrs.ErrNotFound) {
 			return nil, ErrTenderNotFound
 		}
+		if errors.Is(err, repoerrs.ErrConflict) || errors.Is(err, repoerrs.ErrStatusMismatch) {
+			return nil, ErrVersionConflict
+		}
 		return nil, ErrCannotPutStatus
 	}
 
 	return &PutStatusOutput{
-		Id:          tender.Id,
-		Name:        tender.Name,
-		Description: tender.Description,
-		Status:      tender.Status,
-		ServiceType: tender.Type,
-		Version:     tender.Version,
-		CreatedAt:   tender.CreatedAt,
+		Id:           tender.Id,
+		Name:         tender.Name,
+		Description:  tender.Description,
+		Status:       tender.Status,
+		ServiceType:  tender.Type,
+		Version:      tender.Version,
+		CreatedAt:    tender.CreatedAt,
+		EditedBy:     tender.EditedBy,
+		ChangeReason: tender.ChangeReason,
 	}, nil
 }
 
 func (s *TenderService) EditTender(ctx context.Context, input EditTenderInput) (*EditTenderOutput, error) {
-	tender, err := s.tenderRepo.EditTender(ctx, input.Id, input.Name, input.Description, input.ServiceType)
+	tender, err := s.tenderRepo.GetTenderById(ctx, input.Id)
 	if err != nil {
 		if errors.Is(err, repoerrs.ErrNotFound) {
 			return nil, ErrTenderNotFound
 		}
 		return nil, ErrCannotEditTender
 	}
+	// only the deadlines being changed must lie ahead, but the order is
+	// checked against the deadline that is kept
+	if err := validateDeadlines(input.SubmissionDeadline, input.DecisionDeadline, time.Now()); err != nil {
+		return nil, err
+	}
+	submission, decision := tender.SubmissionDeadline, tender.DecisionDeadline
+	if input.SubmissionDeadline != nil {
+		submission = input.SubmissionDeadline
+	}
+	if input.DecisionDeadline != nil {
+		decision = input.DecisionDeadline
+	}
+	if submission != nil && decision != nil && decision.Before(*submission) {
+		return nil, ErrInvalidDeadline
+	}
+	for attempt := 0; attempt < versionRetries; attempt++ {
+		tender, err = s.tenderRepo.EditTender(ctx, input.Id, input.ExpectedVersion, input.Name, input.Description, input.ServiceType, utcTime(input.SubmissionDeadline), utcTime(input.DecisionDeadline), input.EditedBy, input.Reason)
+		if !errors.Is(err, repoerrs.ErrConflict) {
+			break
+		}
+	}
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return nil, ErrTenderNotFound
+		}
+		if errors.Is(err, repoerrs.ErrVersionMismatch) {
+			return nil, ErrVersionMismatch
+		}
+		if errors.Is(err, repoerrs.ErrConflict) {
+			return nil, ErrVersionConflict
+		}
+		return nil, ErrCannotEditTender
+	}
 	return &EditTenderOutput{
-		Id:          tender.Id,
-		Name:        tender.Name,
-		Description: tender.Description,
-		Status:      tender.Status,
-		ServiceType: tender.Type,
-		Version:     tender.Version,
-		CreatedAt:   tender.CreatedAt,
+		Id:                 tender.Id,
+		Name:               tender.Name,
+		Description:        tender.Description,
+		Status:             tender.Status,
+		ServiceType:        tender.Type,
+		Version:            tender.Version,
+		CreatedAt:          tender.CreatedAt,
+		EditedBy:           tender.EditedBy,
+		ChangeReason:       tender.ChangeReason,
+		SubmissionDeadline: tender.SubmissionDeadline,
+		DecisionDeadline:   tender.DecisionDeadline,
 	}, nil
 }
 
 func (s *TenderService) RollbackVersion(ctx context.Context, input RollbackVersionInput) (*RollbackVersionOutput, error) {
-	tender, err := s.tenderRepo.RollbackVersion(ctx, input.Id, input.Version)
+	tender, err := s.tenderRepo.GetTenderById(ctx, input.Id)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return nil, ErrTenderNotFound
+		}
+		return nil, ErrCannotRollback
+	}
+	if isFinal(tenderTransitions, tender.Status) {
+		return nil, ErrInvalidTransition
+	}
+	for attempt := 0; attempt < versionRetries; attempt++ {
+		tender, err = s.tenderRepo.RollbackVersion(ctx, input.Id, input.Version, input.EditedBy, input.Reason)
+		if !errors.Is(err, repoerrs.ErrConflict) {
+			break
+		}
+	}
 	if err != nil {
 		if errors.Is(err, repoerrs.ErrNotFound) {
 			return nil, ErrTenderNotFound
@@ -157,15 +287,21 @@ func (s *TenderService) RollbackVersion(ctx context.Context, input RollbackVersi
 		if errors.Is(err, repoerrs.ErrVersionNotFound) {
 			return nil, ErrVersionNotFound
 		}
+		if errors.Is(err, repoerrs.ErrConflict) {
+			return nil, ErrVersionConflict
+		}
+		return nil, ErrCannotRollback
 	}
 	return &RollbackVersionOutput{
-		Id:          tender.Id,
-		Name:        tender.Name,
-		Description: tender.Description,
-		Status:      tender.Status,
-		ServiceType: tender.Type,
-		Version:     tender.Version,
-		CreatedAt:   tender.CreatedAt,
+		Id:           tender.Id,
+		Name:         tender.Name,
+		Description:  tender.Description,
+		Status:       tender.Status,
+		ServiceType:  tender.Type,
+		Version:      tender.Version,
+		CreatedAt:    tender.CreatedAt,
+		EditedBy:     tender.EditedBy,
+		ChangeReason: tender.ChangeReason,
 	}, nil
 }
 
@@ -176,3 +312,97 @@ func (s *TenderService) GetTenderById(ctx context.Context, id uuid.UUID) (*entit
 	}
 	return tender, nil
 }
+
+func (s *TenderService) GetVersions(ctx context.Context, id uuid.UUID) ([]TenderVersionOutput, error) {
+	tenders, err := s.tenderRepo.GetVersions(ctx, id)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return nil, ErrTenderNotFound
+		}
+		return nil, ErrCannotGetVersions
+	}
+	output := make([]TenderVersionOutput, len(tenders))
+	for i, tender := range tenders {
+		output[i] = TenderVersionOutput{
+			Version:         tender.Version,
+			Name:            tender.Name,
+			Description:     tender.Description,
+			Status:          tender.Status,
+			ServiceType:     tender.Type,
+			CreatorUsername: tender.CreatorUsername,
+			EditedBy:        tender.EditedBy,
+			ChangeReason:    tender.ChangeReason,
+			UpdatedAt:       tender.UpdatedAt.Format(formating.TimeFormat),
+		}
+	}
+	return output, nil
+}
+
+// DiffVersions compares the name, description and service type of two versions.
+func (s *TenderService) DiffVersions(ctx context.Context, input DiffVersionsInput) (*DiffVersionsOutput, error) {
+	from, err := s.tenderRepo.GetVersion(ctx, input.Id, input.From)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrVersionNotFound) {
+			return nil, ErrVersionNotFound
+		}
+		return nil, ErrCannotGetVersions
+	}
+	to, err := s.tenderRepo.GetVersion(ctx, input.Id, input.To)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrVersionNotFound) {
+			return nil, ErrVersionNotFound
+		}
+		return nil, ErrCannotGetVersions
+	}
+	changes := make([]FieldChange, 0)
+	changes = appendChange(changes, "name", from.Name, to.Name)
+	changes = appendChange(changes, "description", from.Description, to.Description)
+	changes = appendChange(changes, "serviceType", from.Type, to.Type)
+	return &DiffVersionsOutput{
+		From:    from.Version,
+		To:      to.Version,
+		Changes: changes,
+	}, nil
+}
+
+// CloseExpiredTenders closes every tender whose decision deadline has passed
+// and cancels its bids that have no decision, returning how many tenders were
+// closed.
+func (s *TenderService) CloseExpiredTenders(ctx context.Context) (int, error) {
+	closed := 0
+	for {
+		ids, err := s.tenderRepo.CloseExpiredTenders(ctx, time.Now().UTC(), expiredTendersBatch, expiredReason)
+		if err != nil {
+			return closed, ErrCannotCloseExpiredTenders
+		}
+		closed += len(ids)
+		if len(ids) < expiredTendersBatch {
+			return closed, nil
+		}
+	}
+}
+
+// validateDeadlines checks that the deadlines that are set lie ahead of now and
+// that decisions are not due before submissions close.
+func validateDeadlines(submission, decision *time.Time, now time.Time) error {
+	if submission != nil && !submission.After(now) {
+		return ErrInvalidDeadline
+	}
+	if decision != nil && !decision.After(now) {
+		return ErrInvalidDeadline
+	}
+	if submission != nil && decision != nil && decision.Before(*submission) {
+		return ErrInvalidDeadline
+	}
+	return nil
+}
+
+// utcTime converts the deadline to UTC, since timestamps are stored without a
+// time zone.
+func utcTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	u := t.UTC()
+	return &u
+}
@@ -1,5 +1,49 @@
 CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
 
+-- employee and organization used to be provisioned outside the service and
+-- tender references organization, so they are created here, before anything
+-- depends on them, and only when absent. The down migration leaves them alone:
+-- they may well predate the service.
+DO
+$$
+    BEGIN
+        CREATE TYPE organization_type AS ENUM (
+            'IE',
+            'LLC',
+            'JSC'
+            );
+    EXCEPTION
+        WHEN duplicate_object THEN NULL;
+    END
+$$;
+
+CREATE TABLE IF NOT EXISTS employee
+(
+    id         UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    username   VARCHAR(50) UNIQUE NOT NULL,
+    first_name VARCHAR(50),
+    last_name  VARCHAR(50),
+    created_at TIMESTAMP        DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP        DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS organization
+(
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name        VARCHAR(100) NOT NULL,
+    description TEXT,
+    type        organization_type,
+    created_at  TIMESTAMP        DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP        DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS organization_responsible
+(
+    id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID REFERENCES organization (id) ON DELETE CASCADE,
+    user_id         UUID REFERENCES employee (id) ON DELETE CASCADE
+);
+
 CREATE TYPE service_type AS ENUM (
     'Construction',
     'Delivery',
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS bid_decision;
+
+DROP TYPE IF EXISTS bid_decision_type;
@@ -0,0 +1,17 @@
+CREATE TYPE bid_decision_type AS ENUM (
+    'Approved',
+    'Rejected'
+    );
+
+CREATE TABLE bid_decision
+(
+    id          UUID              NOT NULL DEFAULT uuid_generate_v4(),
+    bid_id      UUID              NOT NULL,
+    employee_id UUID              NOT NULL REFERENCES employee (id) ON DELETE CASCADE,
+    decision    bid_decision_type NOT NULL,
+    created_at  TIMESTAMP                  DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE (bid_id, employee_id)
+);
+
+CREATE INDEX idx_bid_decision_bid_id_hash ON bid_decision USING HASH (bid_id);
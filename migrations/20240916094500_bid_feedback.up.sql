@@ -0,0 +1,11 @@
+CREATE TABLE bid_feedback
+(
+    id          UUID          NOT NULL DEFAULT uuid_generate_v4(),
+    bid_id      UUID          NOT NULL,
+    employee_id UUID          NOT NULL REFERENCES employee (id) ON DELETE CASCADE,
+    description VARCHAR(1000) NOT NULL,
+    created_at  TIMESTAMP              DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX idx_bid_feedback_bid_id_hash ON bid_feedback USING HASH (bid_id);
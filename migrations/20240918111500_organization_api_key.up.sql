@@ -0,0 +1,13 @@
+CREATE TABLE organization_api_key
+(
+    id              UUID        NOT NULL DEFAULT uuid_generate_v4(),
+    organization_id UUID        NOT NULL REFERENCES organization (id) ON DELETE CASCADE,
+    created_by      UUID        NOT NULL REFERENCES employee (id) ON DELETE CASCADE,
+    key_hash        VARCHAR(64) NOT NULL UNIQUE,
+    scopes          TEXT[]      NOT NULL,
+    created_at      TIMESTAMP            DEFAULT CURRENT_TIMESTAMP,
+    revoked_at      TIMESTAMP,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX idx_organization_api_key_organization_id_hash ON organization_api_key USING HASH (organization_id);
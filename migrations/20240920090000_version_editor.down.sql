@@ -0,0 +1,7 @@
+ALTER TABLE bid
+    DROP COLUMN IF EXISTS change_reason,
+    DROP COLUMN IF EXISTS edited_by;
+
+ALTER TABLE tender
+    DROP COLUMN IF EXISTS change_reason,
+    DROP COLUMN IF EXISTS edited_by;
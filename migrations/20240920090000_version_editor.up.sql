@@ -0,0 +1,7 @@
+ALTER TABLE tender
+    ADD COLUMN edited_by     UUID REFERENCES employee (id) ON DELETE SET NULL,
+    ADD COLUMN change_reason VARCHAR(500);
+
+ALTER TABLE bid
+    ADD COLUMN edited_by     UUID REFERENCES employee (id) ON DELETE SET NULL,
+    ADD COLUMN change_reason VARCHAR(500);
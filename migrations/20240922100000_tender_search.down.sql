@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_tender_search_gin;
+
+DROP FUNCTION IF EXISTS tender_search_vector(VARCHAR, TEXT);
@@ -0,0 +1,11 @@
+CREATE FUNCTION tender_search_vector(name VARCHAR, description TEXT) RETURNS tsvector
+    LANGUAGE sql
+    IMMUTABLE AS
+$$
+SELECT setweight(to_tsvector('russian', name), 'A') ||
+       setweight(to_tsvector('english', name), 'A') ||
+       setweight(to_tsvector('russian', description), 'B') ||
+       setweight(to_tsvector('english', description), 'B')
+$$;
+
+CREATE INDEX idx_tender_search_gin ON tender USING GIN (tender_search_vector(name, description));
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS bid_current;
+
+CREATE INDEX IF NOT EXISTS idx_tender_search_gin ON tender USING GIN (tender_search_vector(name, description));
+
+DROP TABLE IF EXISTS tender_current;
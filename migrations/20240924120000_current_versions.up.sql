@@ -0,0 +1,35 @@
+-- tender and bid keep every version; tender_current and bid_current hold only
+-- the latest one per id and are written in the same statement as the history
+-- row. Both tables of a pair must keep the same columns; the repos copy rows
+-- between them by column name.
+CREATE TABLE tender_current
+(
+    LIKE tender INCLUDING DEFAULTS,
+    PRIMARY KEY (id)
+);
+
+INSERT INTO tender_current
+SELECT DISTINCT ON (id) *
+FROM tender
+ORDER BY id, version DESC;
+
+CREATE INDEX idx_tender_current_creator_username_hash ON tender_current USING HASH (creator_username);
+CREATE INDEX idx_tender_current_organization_id_hash ON tender_current USING HASH (organization_id);
+CREATE INDEX idx_tender_current_status_name ON tender_current (status, name, id);
+CREATE INDEX idx_tender_current_search_gin ON tender_current USING GIN (tender_search_vector(name, description));
+
+DROP INDEX IF EXISTS idx_tender_search_gin;
+
+CREATE TABLE bid_current
+(
+    LIKE bid INCLUDING DEFAULTS,
+    PRIMARY KEY (id)
+);
+
+INSERT INTO bid_current
+SELECT DISTINCT ON (id) *
+FROM bid
+ORDER BY id, version DESC;
+
+CREATE INDEX idx_bid_current_tender_id_hash ON bid_current USING HASH (tender_id);
+CREATE INDEX idx_bid_current_author_id_hash ON bid_current USING HASH (author_id);
@@ -0,0 +1,12 @@
+ALTER TABLE tender_current
+    DROP CONSTRAINT IF EXISTS tender_current_organization_id_fkey;
+
+DROP INDEX IF EXISTS idx_organization_responsible_user_id_hash;
+
+ALTER TABLE organization_responsible
+    DROP CONSTRAINT IF EXISTS organization_responsible_organization_user_key;
+
+ALTER TABLE organization_responsible
+    DROP COLUMN IF EXISTS role;
+
+DROP TYPE IF EXISTS responsible_role;
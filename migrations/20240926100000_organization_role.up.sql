@@ -0,0 +1,27 @@
+CREATE TYPE responsible_role AS ENUM (
+    'Admin',
+    'Member'
+    );
+
+-- responsibles that predate roles keep managing their organization
+ALTER TABLE organization_responsible
+    ADD COLUMN role responsible_role NOT NULL DEFAULT 'Admin';
+ALTER TABLE organization_responsible
+    ALTER COLUMN role SET DEFAULT 'Member';
+
+DELETE
+FROM organization_responsible a
+    USING organization_responsible b
+WHERE a.organization_id = b.organization_id
+  AND a.user_id = b.user_id
+  AND a.ctid > b.ctid;
+
+ALTER TABLE organization_responsible
+    ADD CONSTRAINT organization_responsible_organization_user_key UNIQUE (organization_id, user_id);
+
+CREATE INDEX IF NOT EXISTS idx_organization_responsible_user_id_hash ON organization_responsible USING HASH (user_id);
+
+-- tender_current was created without constraints, so deleting an organization
+-- must remove its current rows the same way it removes the history
+ALTER TABLE tender_current
+    ADD CONSTRAINT tender_current_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES organization (id) ON DELETE CASCADE;
@@ -0,0 +1,2 @@
+ALTER TABLE employee
+    ADD COLUMN deactivated_at TIMESTAMP;
@@ -0,0 +1,5 @@
+ALTER TABLE bid_current
+    DROP COLUMN IF EXISTS organization_id;
+
+ALTER TABLE bid
+    DROP COLUMN IF EXISTS organization_id;
@@ -0,0 +1,23 @@
+-- organization bids record the organization they were submitted for, since an
+-- author may be responsible for several. The column is added to both tables
+-- so bid and bid_current keep the same column order.
+ALTER TABLE bid
+    ADD COLUMN organization_id UUID;
+ALTER TABLE bid_current
+    ADD COLUMN organization_id UUID;
+
+UPDATE bid
+SET organization_id = (SELECT organization_id
+                       FROM organization_responsible
+                       WHERE user_id = bid.author_id
+                       ORDER BY organization_id
+                       LIMIT 1)
+WHERE author_type = 'Organization';
+
+UPDATE bid_current
+SET organization_id = (SELECT organization_id
+                       FROM organization_responsible
+                       WHERE user_id = bid_current.author_id
+                       ORDER BY organization_id
+                       LIMIT 1)
+WHERE author_type = 'Organization';
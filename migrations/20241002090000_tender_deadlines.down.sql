@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_tender_current_expires_at;
+
+ALTER TABLE tender_current
+    DROP COLUMN IF EXISTS decision_deadline,
+    DROP COLUMN IF EXISTS submission_deadline;
+
+ALTER TABLE tender
+    DROP COLUMN IF EXISTS decision_deadline,
+    DROP COLUMN IF EXISTS submission_deadline;
@@ -0,0 +1,11 @@
+-- added to both tables so tender and tender_current keep the same column order
+ALTER TABLE tender
+    ADD COLUMN submission_deadline TIMESTAMP,
+    ADD COLUMN decision_deadline   TIMESTAMP;
+ALTER TABLE tender_current
+    ADD COLUMN submission_deadline TIMESTAMP,
+    ADD COLUMN decision_deadline   TIMESTAMP;
+
+-- lets the deadline scheduler find expired tenders without a full scan
+CREATE INDEX idx_tender_current_expires_at ON tender_current (COALESCE(decision_deadline, submission_deadline))
+    WHERE status <> 'Closed';
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_tender_current_decision_deadline;
+
+CREATE INDEX idx_tender_current_expires_at ON tender_current (COALESCE(decision_deadline, submission_deadline))
+    WHERE status <> 'Closed';
@@ -0,0 +1,6 @@
+-- tenders are closed automatically at their decision deadline only, so the
+-- scheduler no longer looks at the submission deadline
+DROP INDEX IF EXISTS idx_tender_current_expires_at;
+
+CREATE INDEX idx_tender_current_decision_deadline ON tender_current (decision_deadline)
+    WHERE status <> 'Closed' AND decision_deadline IS NOT NULL;
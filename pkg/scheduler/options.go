@@ -0,0 +1,17 @@
+package scheduler
+
+import "time"
+
+type Option func(*Scheduler)
+
+func Interval(interval time.Duration) Option {
+	return func(s *Scheduler) {
+		s.interval = interval
+	}
+}
+
+func ShutdownTimeout(timeout time.Duration) Option {
+	return func(s *Scheduler) {
+		s.shutdownTimeout = timeout
+	}
+}
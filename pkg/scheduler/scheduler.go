@@ -0,0 +1,73 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const (
+	defaultInterval        = time.Minute
+	defaultShutdownTimeout = 3 * time.Second
+)
+
+var ErrShutdownTimeout = errors.New("scheduler: job did not stop before the shutdown timeout")
+
+// Job is run by the scheduler. Its context is canceled on shutdown.
+type Job func(ctx context.Context)
+
+// Scheduler runs a job right away and then once per interval, never running
+// two instances of it at the same time.
+type Scheduler struct {
+	job             Job
+	interval        time.Duration
+	shutdownTimeout time.Duration
+	cancel          context.CancelFunc
+	done            chan struct{}
+}
+
+func New(job Job, opts ...Option) *Scheduler {
+	s := &Scheduler{
+		job:             job,
+		interval:        defaultInterval,
+		shutdownTimeout: defaultShutdownTimeout,
+		done:            make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.start()
+
+	return s
+}
+
+func (s *Scheduler) start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			s.job(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Shutdown stops scheduling the job and waits for a running one to return.
+func (s *Scheduler) Shutdown() error {
+	s.cancel()
+	select {
+	case <-s.done:
+		return nil
+	case <-time.After(s.shutdownTimeout):
+		return ErrShutdownTimeout
+	}
+}